@@ -0,0 +1,423 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+// pulpctl runs the common pulp workflows from the command line:
+//
+//	pulpctl [flags] repo list|create|delete|sync|publish
+//	pulpctl [flags] task list|watch
+//	pulpctl [flags] unit copy
+//	pulpctl [flags] orphan list|clean
+//
+// The server and the credentials are taken from the flags or from the
+// PULP_HOST, PULP_USER and PULP_PASSWORD environment variables.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/msutter/go-pulp/pulp"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `usage: pulpctl [flags] <command> <action> [args]
+
+commands:
+  repo list
+  repo create [-type yum] [-feed url] [-name name] <repo>
+  repo delete <repo>...
+  repo sync [-concurrency n] <repo>...
+  repo publish <repo>...
+  repo apply [-dry-run] <config.json>
+  task list
+  task watch <task>...
+  unit copy [-type rpm] <source> <destination>
+  orphan list <type>
+  orphan clean [-type type]
+
+flags:
+`
+
+var output = "table"
+
+func main() {
+	host := flag.String("host", os.Getenv("PULP_HOST"), "pulp server `host`")
+	user := flag.String("user", os.Getenv("PULP_USER"), "api user")
+	password := flag.String("password", os.Getenv("PULP_PASSWORD"), "api password")
+	insecure := flag.Bool("insecure", false, "skip the verification of the server certificate")
+	noSSL := flag.Bool("no-ssl", false, "connect over plain http")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout of the api requests")
+	flag.StringVar(&output, "o", output, "output `format`, table or json")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() < 2 || *host == "" || (output != "table" && output != "json") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	opts := []pulp.ClientOption{pulp.WithTimeout(*timeout)}
+	if *user != "" {
+		opts = append(opts, pulp.WithBasicAuth(*user, *password))
+	}
+	if *insecure {
+		opts = append(opts, pulp.WithInsecureSkipVerify())
+	}
+	if *noSSL {
+		opts = append(opts, pulp.WithoutSSL())
+	}
+
+	client, err := pulp.NewClient(*host, opts...)
+	if err != nil {
+		fatal(err)
+	}
+
+	cmd := flag.Arg(0) + " " + flag.Arg(1)
+	args := flag.Args()[2:]
+
+	switch cmd {
+	case "repo list":
+		err = repoList(client)
+	case "repo create":
+		err = repoCreate(client, args)
+	case "repo delete":
+		err = repoDelete(client, args)
+	case "repo sync":
+		err = repoSync(client, args)
+	case "repo publish":
+		err = repoPublish(client, args)
+	case "repo apply":
+		err = repoApply(client, args)
+	case "task list":
+		err = taskList(client)
+	case "task watch":
+		err = taskWatch(client, args)
+	case "unit copy":
+		err = unitCopy(client, args)
+	case "orphan list":
+		err = orphanList(client, args)
+	case "orphan clean":
+		err = orphanClean(client, args)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "pulpctl:", err)
+	os.Exit(1)
+}
+
+// render writes v as json, or the rows as a table.
+func render(v interface{}, header []string, rows [][]string) {
+	if output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(v); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+}
+
+func printTasks(tasks []*pulp.Task) {
+	rows := make([][]string, len(tasks))
+	for i, t := range tasks {
+		rows[i] = []string{t.Id, t.TaskType, t.State, t.StartTime.String(), t.FinishTime.String()}
+	}
+	render(tasks, []string{"ID", "TYPE", "STATE", "STARTED", "FINISHED"}, rows)
+}
+
+func repoList(client *pulp.Client) error {
+	repos, _, err := client.Repositories.ListRepositories(&pulp.ListRepositoriesOptions{Importers: true})
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, len(repos))
+	for i, r := range repos {
+		importer := ""
+		if len(r.Importers) > 0 {
+			importer = r.Importers[0].ImporterTypeId
+		}
+		rows[i] = []string{r.Id, r.Name, importer, r.LastUnitAdded.String()}
+	}
+	render(repos, []string{"ID", "NAME", "IMPORTER", "LAST UNIT ADDED"}, rows)
+	return nil
+}
+
+func repoCreate(client *pulp.Client, args []string) error {
+	fs := flag.NewFlagSet("repo create", flag.ExitOnError)
+	typ := fs.String("type", "yum", "repository type, yum, iso, docker, puppet or python")
+	feed := fs.String("feed", "", "feed `url` of the importer")
+	name := fs.String("name", "", "display name")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("repo create takes one repository id")
+	}
+	id := fs.Arg(0)
+
+	opt := &pulp.CreateRepositoryOptions{
+		Id:             id,
+		DisplayName:    *name,
+		ImporterTypeId: *typ + "_importer",
+		ImporterConfig: map[string]interface{}{},
+	}
+	if *feed != "" {
+		opt.ImporterConfig = map[string]interface{}{"feed": *feed}
+	}
+
+	switch *typ {
+	case "yum":
+		opt.Notes = map[string]string{pulp.RepoTypeNote: pulp.RpmRepoType}
+		opt.Distributors = []*pulp.AddDistributorOptions{{
+			DistributorId:     pulp.YumDistributorType,
+			DistributorTypeId: pulp.YumDistributorType,
+			DistributorConfig: &pulp.YumDistributorConfig{RelativeUrl: id, Http: true, Https: true},
+			AutoPublish:       true,
+		}}
+	case "iso":
+		opt.Notes = map[string]string{pulp.RepoTypeNote: pulp.IsoRepoType}
+		opt.Distributors = []*pulp.AddDistributorOptions{{
+			DistributorId:     pulp.IsoDistributorType,
+			DistributorTypeId: pulp.IsoDistributorType,
+			DistributorConfig: map[string]interface{}{"serve_http": true, "serve_https": true},
+			AutoPublish:       true,
+		}}
+	}
+
+	r, _, err := client.Repositories.CreateRepository(opt)
+	if err != nil {
+		return err
+	}
+	render(r, []string{"ID", "NAME"}, [][]string{{r.Id, r.Name}})
+	return nil
+}
+
+func repoDelete(client *pulp.Client, args []string) error {
+	var tasks []*pulp.Task
+	for _, id := range args {
+		cr, _, err := client.Repositories.DeleteRepository(id)
+		if err != nil {
+			return err
+		}
+		t, err := cr.WaitAll(client, nil)
+		tasks = append(tasks, t...)
+		if err != nil {
+			return err
+		}
+	}
+	printTasks(tasks)
+	return nil
+}
+
+func repoSync(client *pulp.Client, args []string) error {
+	fs := flag.NewFlagSet("repo sync", flag.ExitOnError)
+	concurrency := fs.Int("concurrency", 4, "number of repositories synced at a time")
+	fs.Parse(args)
+
+	progress := func(repo string, t *pulp.Task) {
+		fmt.Fprintf(os.Stderr, "%s: %s %s\n", repo, t.Id, t.State)
+	}
+	reports, err := client.Repositories.SyncAll(fs.Args(), *concurrency, progress)
+
+	rows := make([][]string, len(reports))
+	for i, r := range reports {
+		state := "ok"
+		if r.Err != nil {
+			state = r.Err.Error()
+		}
+		rows[i] = []string{r.RepoId, r.Duration.Round(time.Second).String(), state}
+	}
+	render(reports, []string{"REPO", "DURATION", "RESULT"}, rows)
+	return err
+}
+
+func repoPublish(client *pulp.Client, args []string) error {
+	var tasks []*pulp.Task
+	for _, id := range args {
+		r, err := client.Repositories.Repo(id)
+		if err != nil {
+			return err
+		}
+		t, err := r.PublishAll(nil)
+		tasks = append(tasks, t...)
+		if err != nil {
+			return err
+		}
+	}
+	printTasks(tasks)
+	return nil
+}
+
+// repoApply converges the repositories to a json config, see
+// pulp.ApplyConfig.
+func repoApply(client *pulp.Client, args []string) error {
+	fs := flag.NewFlagSet("repo apply", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "print the changes without making them")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("repo apply takes a config file")
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cfg, err := pulp.LoadApplyConfig(f)
+	if err != nil {
+		return err
+	}
+
+	var plan *pulp.Plan
+	if *dryRun {
+		plan, err = client.Plan(cfg)
+	} else {
+		plan, err = client.Apply(cfg)
+	}
+	if plan != nil {
+		if output == "json" {
+			render(plan, nil, nil)
+		} else {
+			fmt.Println(plan)
+		}
+	}
+	return err
+}
+
+func taskList(client *pulp.Client) error {
+	tasks, _, err := client.Tasks.ListTasks()
+	if err != nil {
+		return err
+	}
+	printTasks(tasks)
+	return nil
+}
+
+// taskWatch prints the state transitions of the tasks until they finish.
+func taskWatch(client *pulp.Client, args []string) error {
+	if len(args) == 0 {
+		return errors.New("task watch takes at least one task id")
+	}
+
+	w := client.NewTaskWatcher(nil)
+	defer w.Stop()
+	w.Watch(args...)
+
+	var failed []string
+	left := len(args)
+	for e := range w.Events() {
+		if e.Err != nil {
+			return e.Err
+		}
+		fmt.Printf("%s: %s (%.0f%%)\n", e.TaskId, e.Task.State, e.Task.PercentComplete())
+		if e.Task.Finished() {
+			if e.Task.State == pulp.TaskError {
+				failed = append(failed, e.TaskId)
+			}
+			if left--; left == 0 {
+				break
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed tasks: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func unitCopy(client *pulp.Client, args []string) error {
+	fs := flag.NewFlagSet("unit copy", flag.ExitOnError)
+	typ := fs.String("type", "", "copy only the units of this `type`, like rpm")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		return errors.New("unit copy takes a source and a destination repository")
+	}
+
+	criteria := pulp.NewUnitCriteria()
+	if *typ != "" {
+		criteria = pulp.NewUnitCriteria(*typ)
+	}
+
+	cr, _, err := client.Units.CopyUnits(fs.Arg(0), fs.Arg(1), criteria, nil)
+	if err != nil {
+		return err
+	}
+	tasks, err := cr.WaitAll(client, nil)
+	printTasks(tasks)
+	return err
+}
+
+func orphanList(client *pulp.Client, args []string) error {
+	if len(args) != 1 {
+		return errors.New("orphan list takes a content type")
+	}
+
+	orphans, _, err := client.Orphans.ListOrphans(args[0])
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, len(orphans))
+	for i, o := range orphans {
+		rows[i] = []string{o.Id, o.ContentTypeId, o.StoragePath}
+	}
+	render(orphans, []string{"ID", "TYPE", "PATH"}, rows)
+	return nil
+}
+
+func orphanClean(client *pulp.Client, args []string) error {
+	fs := flag.NewFlagSet("orphan clean", flag.ExitOnError)
+	typ := fs.String("type", "", "remove only the orphans of this `type`")
+	fs.Parse(args)
+
+	var cr *pulp.CallReport
+	var err error
+	if *typ != "" {
+		cr, _, err = client.Orphans.DeleteOrphansOfType(*typ)
+	} else {
+		cr, _, err = client.Orphans.DeleteAllOrphans()
+	}
+	if err != nil {
+		return err
+	}
+
+	tasks, err := cr.WaitAll(client, nil)
+	printTasks(tasks)
+	return err
+}
@@ -13,11 +13,14 @@ func main() {
 	apiPasswd := "admin"
 	apiEndpoint := "pulp-lab-11.test"
 
-	DisableSsl := false
-	SkipSslVerify := true
-
 	// create the client
-	client, err := pulp.NewClient(apiEndpoint, apiUser, apiPasswd, DisableSsl, SkipSslVerify, nil)
+	client, err := pulp.NewClient(apiEndpoint,
+		pulp.WithBasicAuth(apiUser, apiPasswd),
+		pulp.WithInsecureSkipVerify(),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// repository options
 	ro := &pulp.GetRepositoryOptions{
@@ -52,7 +55,7 @@ func main() {
 		fmt.Printf("state: %v\n", task.State)
 		fmt.Printf("progressReport: %v\n", task.ProgressReport)
 
-		var importer *pulp.Importer
+		var importer *pulp.SyncProgress
 		if task.Importer() == "yum" {
 			importer = task.ProgressReport.YumImporter
 		}
@@ -63,6 +66,7 @@ func main() {
 		fmt.Printf("importer: %v\n", task.Importer())
 		fmt.Printf("item Total: %v\n", importer.Content.ItemsTotal)
 		fmt.Printf("item Left: %v\n", importer.Content.ItemsLeft)
+		fmt.Printf("step: %v (%.0f%%)\n", task.CurrentStep(), task.PercentComplete())
 		state = task.State
 		time.Sleep(500 * time.Millisecond)
 		if terr != nil {
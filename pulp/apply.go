@@ -0,0 +1,468 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ApplyConfig is the desired state of the repositories of a server, e.g.
+//
+//	{
+//	  "repositories": [{
+//	    "id": "centos7-base",
+//	    "display_name": "CentOS 7 base",
+//	    "importer": {
+//	      "type_id": "yum_importer",
+//	      "config": {"feed": "http://mirror.centos.org/centos/7/os/x86_64/"},
+//	      "sync_schedules": ["PT6H"]
+//	    },
+//	    "distributors": [{
+//	      "id": "yum_distributor",
+//	      "type_id": "yum_distributor",
+//	      "config": {"relative_url": "centos7/base", "http": true, "https": true},
+//	      "auto_publish": true
+//	    }]
+//	  }]
+//	}
+//
+// The distributors and the schedules of a repository are managed as a whole:
+// the ones missing from the config are removed. Only the notes and the
+// importer and distributor config keys listed in the config are managed.
+type ApplyConfig struct {
+	Repositories []*RepoSpec `json:"repositories"`
+
+	// Prune deletes the repositories of the server missing from the config.
+	Prune bool `json:"prune,omitempty"`
+}
+
+// RepoSpec defines a repository, as managed by Apply or exported by
+// RepositoriesService.ExportDefinition.
+type RepoSpec struct {
+	Id           string             `json:"id"`
+	DisplayName  string             `json:"display_name,omitempty"`
+	Description  string             `json:"description,omitempty"`
+	Notes        map[string]string  `json:"notes,omitempty"`
+	Importer     *ImporterSpec      `json:"importer,omitempty"`
+	Distributors []*DistributorSpec `json:"distributors,omitempty"`
+}
+
+type ImporterSpec struct {
+	TypeId        string                 `json:"type_id"`
+	Config        map[string]interface{} `json:"config,omitempty"`
+	SyncSchedules []string               `json:"sync_schedules,omitempty"`
+}
+
+type DistributorSpec struct {
+	Id               string                 `json:"id"`
+	TypeId           string                 `json:"type_id"`
+	Config           map[string]interface{} `json:"config,omitempty"`
+	AutoPublish      bool                   `json:"auto_publish,omitempty"`
+	PublishSchedules []string               `json:"publish_schedules,omitempty"`
+}
+
+// LoadApplyConfig reads a json config.
+func LoadApplyConfig(r io.Reader) (*ApplyConfig, error) {
+	cfg := new(ApplyConfig)
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("pulp: invalid config: %v", err)
+	}
+
+	ids := make(map[string]bool)
+	for _, spec := range cfg.Repositories {
+		if spec.Id == "" {
+			return nil, fmt.Errorf("pulp: invalid config: repository without id")
+		}
+		if ids[spec.Id] {
+			return nil, fmt.Errorf("pulp: invalid config: duplicate repository %s", spec.Id)
+		}
+		ids[spec.Id] = true
+	}
+	return cfg, nil
+}
+
+const (
+	ChangeCreate = "create"
+	ChangeUpdate = "update"
+	ChangeDelete = "delete"
+)
+
+// Change is a change of the server planned by Client.Plan. Resource is one
+// of repository, importer, distributor, sync_schedule or publish_schedule;
+// Id is the id of the importer, distributor or the schedule.
+type Change struct {
+	Action   string `json:"action"`
+	Resource string `json:"resource"`
+	RepoId   string `json:"repo_id"`
+	Id       string `json:"id,omitempty"`
+
+	apply func() error
+}
+
+func (c *Change) String() string {
+	sign := map[string]string{ChangeCreate: "+", ChangeUpdate: "~", ChangeDelete: "-"}[c.Action]
+	name := c.RepoId
+	if c.Id != "" {
+		name += "/" + c.Id
+	}
+	return fmt.Sprintf("%s %s %s", sign, c.Resource, name)
+}
+
+// Plan lists the changes converging the server to a config, in the order
+// they are applied.
+type Plan struct {
+	Changes []*Change `json:"changes"`
+}
+
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+func (p *Plan) String() string {
+	if p.Empty() {
+		return "no changes"
+	}
+
+	lines := make([]string, len(p.Changes))
+	for i, c := range p.Changes {
+		lines[i] = c.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (p *Plan) add(action string, resource string, repoId string, id string, apply func() error) {
+	p.Changes = append(p.Changes, &Change{
+		Action:   action,
+		Resource: resource,
+		RepoId:   repoId,
+		Id:       id,
+		apply:    apply,
+	})
+}
+
+// Plan compares the server to cfg and returns the changes Apply would make,
+// without making them.
+func (c *Client) Plan(cfg *ApplyConfig) (*Plan, error) {
+	live, _, err := c.Repositories.ListRepositories(&ListRepositoriesOptions{Details: true})
+	if err != nil {
+		return nil, err
+	}
+
+	repos := make(map[string]*Repository)
+	for _, r := range live {
+		repos[r.Id] = r
+	}
+
+	p := new(Plan)
+	wanted := make(map[string]bool)
+	for _, spec := range cfg.Repositories {
+		wanted[spec.Id] = true
+		if r, ok := repos[spec.Id]; ok {
+			if err := c.planUpdate(p, spec, r); err != nil {
+				return nil, err
+			}
+		} else {
+			c.planCreate(p, spec)
+		}
+	}
+
+	if cfg.Prune {
+		var ids []string
+		for id := range repos {
+			if !wanted[id] {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			id := id
+			p.add(ChangeDelete, "repository", id, "", func() error {
+				return c.waitCall(c.Repositories.DeleteRepository(id))
+			})
+		}
+	}
+
+	return p, nil
+}
+
+// Apply makes the changes converging the server to cfg. It stops at the
+// first failing change, the changes before it are left applied.
+func (c *Client) Apply(cfg *ApplyConfig) (*Plan, error) {
+	p, err := c.Plan(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, p.apply()
+}
+
+func (p *Plan) apply() error {
+	for _, ch := range p.Changes {
+		if err := ch.apply(); err != nil {
+			return fmt.Errorf("pulp: %s: %w", ch, err)
+		}
+	}
+	return nil
+}
+
+// waitCall waits for the tasks spawned by an asynchronous call.
+func (c *Client) waitCall(cr *CallReport, _ *Response, err error) error {
+	if err != nil {
+		return err
+	}
+	_, err = cr.WaitAll(c, nil)
+	return err
+}
+
+func (c *Client) planCreate(p *Plan, spec *RepoSpec) {
+	opt := &CreateRepositoryOptions{
+		Id:          spec.Id,
+		DisplayName: spec.DisplayName,
+		Description: spec.Description,
+		Notes:       spec.Notes,
+	}
+	if spec.Importer != nil {
+		opt.ImporterTypeId = spec.Importer.TypeId
+		opt.ImporterConfig = spec.Importer.Config
+	}
+	for _, d := range spec.Distributors {
+		opt.Distributors = append(opt.Distributors, d.addOptions())
+	}
+
+	p.add(ChangeCreate, "repository", spec.Id, "", func() error {
+		_, _, err := c.Repositories.CreateRepository(opt)
+		return err
+	})
+
+	// pulp names the importer after its type
+	if spec.Importer != nil {
+		c.planSyncSchedules(p, spec.Id, spec.Importer.TypeId, spec.Importer.SyncSchedules, nil)
+	}
+	for _, d := range spec.Distributors {
+		c.planPublishSchedules(p, spec.Id, d.Id, d.PublishSchedules, nil)
+	}
+}
+
+func (c *Client) planUpdate(p *Plan, spec *RepoSpec, r *Repository) error {
+	delta := new(UpdateRepositoryOptions)
+	changed := false
+	if spec.DisplayName != "" && spec.DisplayName != r.Name {
+		delta.DisplayName = String(spec.DisplayName)
+		changed = true
+	}
+	if spec.Description != "" && spec.Description != r.Description {
+		delta.Description = String(spec.Description)
+		changed = true
+	}
+	for k, v := range spec.Notes {
+		if have, ok := r.Notes[k]; !ok || have != v {
+			if delta.Notes == nil {
+				delta.Notes = make(map[string]interface{})
+			}
+			delta.Notes[k] = v
+			changed = true
+		}
+	}
+	if changed {
+		p.add(ChangeUpdate, "repository", spec.Id, "", func() error {
+			return c.waitCall(c.Repositories.UpdateRepository(spec.Id, delta))
+		})
+	}
+
+	if err := c.planImporter(p, spec, r); err != nil {
+		return err
+	}
+	return c.planDistributors(p, spec, r)
+}
+
+func (c *Client) planImporter(p *Plan, spec *RepoSpec, r *Repository) error {
+	var live *Importer
+	if len(r.Importers) > 0 {
+		live = r.Importers[0]
+	}
+	want := spec.Importer
+
+	if live != nil && (want == nil || want.TypeId != live.ImporterTypeId) {
+		id := live.Id
+		p.add(ChangeDelete, "importer", spec.Id, id, func() error {
+			return c.waitCall(c.Repositories.RemoveImporter(spec.Id, id))
+		})
+		live = nil
+	}
+	if want == nil {
+		return nil
+	}
+
+	if live == nil {
+		p.add(ChangeCreate, "importer", spec.Id, want.TypeId, func() error {
+			return c.waitCall(c.Repositories.AddImporter(spec.Id, &AddImporterOptions{
+				ImporterTypeId: want.TypeId,
+				ImporterConfig: want.Config,
+			}))
+		})
+		c.planSyncSchedules(p, spec.Id, want.TypeId, want.SyncSchedules, nil)
+		return nil
+	}
+
+	if configDiffers(want.Config, live.Config) {
+		p.add(ChangeUpdate, "importer", spec.Id, live.Id, func() error {
+			return c.waitCall(c.Repositories.UpdateImporter(spec.Id, live.Id, want.Config))
+		})
+	}
+
+	schedules, _, err := c.Repositories.ListSyncSchedules(spec.Id, live.Id)
+	if err != nil {
+		return err
+	}
+	c.planSyncSchedules(p, spec.Id, live.Id, want.SyncSchedules, schedules)
+	return nil
+}
+
+func (c *Client) planDistributors(p *Plan, spec *RepoSpec, r *Repository) error {
+	live := make(map[string]*Distributor)
+	for _, d := range r.Distributors {
+		live[d.Id] = d
+	}
+
+	wanted := make(map[string]bool)
+	for _, want := range spec.Distributors {
+		want := want
+		wanted[want.Id] = true
+
+		d, ok := live[want.Id]
+		if ok && d.DistributorTypeId != want.TypeId {
+			p.add(ChangeDelete, "distributor", spec.Id, want.Id, func() error {
+				return c.waitCall(c.Repositories.RemoveDistributor(spec.Id, want.Id))
+			})
+			ok = false
+		}
+
+		if !ok {
+			p.add(ChangeCreate, "distributor", spec.Id, want.Id, func() error {
+				_, _, err := c.Repositories.AddDistributor(spec.Id, want.addOptions())
+				return err
+			})
+			c.planPublishSchedules(p, spec.Id, want.Id, want.PublishSchedules, nil)
+			continue
+		}
+
+		if configDiffers(want.Config, d.Config) || want.AutoPublish != d.AutoPublish {
+			p.add(ChangeUpdate, "distributor", spec.Id, want.Id, func() error {
+				return c.waitCall(c.Repositories.UpdateDistributor(spec.Id, want.Id, &UpdateDistributorOptions{
+					DistributorConfig: want.Config,
+					AutoPublish:       Bool(want.AutoPublish),
+				}))
+			})
+		}
+
+		schedules, _, err := c.Repositories.ListPublishSchedules(spec.Id, want.Id)
+		if err != nil {
+			return err
+		}
+		c.planPublishSchedules(p, spec.Id, want.Id, want.PublishSchedules, schedules)
+	}
+
+	for _, d := range r.Distributors {
+		if wanted[d.Id] {
+			continue
+		}
+		id := d.Id
+		p.add(ChangeDelete, "distributor", spec.Id, id, func() error {
+			return c.waitCall(c.Repositories.RemoveDistributor(spec.Id, id))
+		})
+	}
+	return nil
+}
+
+func (c *Client) planSyncSchedules(p *Plan, repoId string, importer string, want []string, live []*Schedule) {
+	planSchedules(p, "sync_schedule", repoId, want, live,
+		func(schedule string) error {
+			_, _, err := c.Repositories.CreateSyncSchedule(repoId, importer, &ScheduleOptions{Schedule: schedule})
+			return err
+		},
+		func(id string) error {
+			_, err := c.Repositories.DeleteSyncSchedule(repoId, importer, id)
+			return err
+		})
+}
+
+func (c *Client) planPublishSchedules(p *Plan, repoId string, distributor string, want []string, live []*Schedule) {
+	planSchedules(p, "publish_schedule", repoId, want, live,
+		func(schedule string) error {
+			_, _, err := c.Repositories.CreatePublishSchedule(repoId, distributor, &ScheduleOptions{Schedule: schedule})
+			return err
+		},
+		func(id string) error {
+			_, err := c.Repositories.DeletePublishSchedule(repoId, distributor, id)
+			return err
+		})
+}
+
+// planSchedules matches the schedules on their ISO8601 interval, creating
+// the missing ones and deleting the others.
+func planSchedules(p *Plan, resource string, repoId string, want []string, live []*Schedule, create func(string) error, remove func(string) error) {
+	existing := make(map[string]bool)
+	for _, s := range live {
+		existing[s.Schedule] = true
+	}
+	wanted := make(map[string]bool)
+	for _, s := range want {
+		wanted[s] = true
+	}
+
+	for _, s := range want {
+		if existing[s] {
+			continue
+		}
+		s := s
+		p.add(ChangeCreate, resource, repoId, s, func() error {
+			return create(s)
+		})
+	}
+	for _, s := range live {
+		if wanted[s.Schedule] {
+			continue
+		}
+		id := s.Id
+		p.add(ChangeDelete, resource, repoId, id, func() error {
+			return remove(id)
+		})
+	}
+}
+
+func (d *DistributorSpec) addOptions() *AddDistributorOptions {
+	return &AddDistributorOptions{
+		DistributorId:     d.Id,
+		DistributorTypeId: d.TypeId,
+		DistributorConfig: d.Config,
+		AutoPublish:       d.AutoPublish,
+	}
+}
+
+// configDiffers reports whether one of the keys of want has another value
+// in have.
+func configDiffers(want map[string]interface{}, have map[string]interface{}) bool {
+	return len(configDiff(want, have)) > 0
+}
@@ -0,0 +1,204 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuthProvider authenticates every request sent by the client. It is
+// called with a copy of the request right before each attempt to send it,
+// so that signatures, like the OAuth nonce and timestamp, are never
+// replayed.
+type AuthProvider interface {
+	Authenticate(req *http.Request) error
+}
+
+// BasicAuth authenticates requests with http basic authentication.
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/authentication.html
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+func (a BasicAuth) String() string {
+	return Stringify(a)
+}
+
+func (a *BasicAuth) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(a.Username, a.Password)
+	return nil
+}
+
+// OAuth signs requests with pulp's two-legged OAuth 1.0 (HMAC-SHA1). The
+// request is executed as User, which is sent in the pulp-user header.
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/user-guide/admin-client/authentication.html
+type OAuth struct {
+	Key    string
+	Secret string
+	User   string
+}
+
+func (a OAuth) String() string {
+	return Stringify(a)
+}
+
+func (a *OAuth) Authenticate(req *http.Request) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	params := map[string]string{
+		"oauth_consumer_key":     a.Key,
+		"oauth_nonce":            hex.EncodeToString(nonce),
+		"oauth_signature_method": "HMAC-SHA1",
+		"oauth_timestamp":        strconv.FormatInt(time.Now().Unix(), 10),
+		"oauth_version":          "1.0",
+	}
+	params["oauth_signature"] = a.signature(req, params)
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := make([]string, len(keys))
+	for i, k := range keys {
+		h[i] = fmt.Sprintf(`%s="%s"`, k, oauthEscape(params[k]))
+	}
+
+	req.Header.Set("Authorization", "OAuth "+strings.Join(h, ", "))
+	if a.User != "" {
+		req.Header.Set("pulp-user", a.User)
+	}
+	return nil
+}
+
+// signature computes the signature as described in RFC 5849 section 3.4
+func (a *OAuth) signature(req *http.Request, oauthParams map[string]string) string {
+	var params []string
+	for k, v := range oauthParams {
+		params = append(params, oauthEscape(k)+"="+oauthEscape(v))
+	}
+	for k, vs := range req.URL.Query() {
+		for _, v := range vs {
+			params = append(params, oauthEscape(k)+"="+oauthEscape(v))
+		}
+	}
+	sort.Strings(params)
+
+	path := req.URL.Opaque
+	if path == "" {
+		path = req.URL.EscapedPath()
+	}
+	base := fmt.Sprintf("%s://%s%s", strings.ToLower(req.URL.Scheme), strings.ToLower(req.URL.Host), path)
+
+	s := strings.Join([]string{
+		strings.ToUpper(req.Method),
+		oauthEscape(base),
+		oauthEscape(strings.Join(params, "&")),
+	}, "&")
+
+	mac := hmac.New(sha1.New, []byte(oauthEscape(a.Secret)+"&"))
+	mac.Write([]byte(s))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// oauthEscape percent-encodes all but the unreserved characters (RFC 3986)
+func oauthEscape(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
+
+// TokenAuth authenticates requests with a pre-issued token, sent in the
+// Authorization header. Scheme defaults to Bearer.
+type TokenAuth struct {
+	Token  string
+	Scheme string
+}
+
+func (t TokenAuth) String() string {
+	return Stringify(t)
+}
+
+func (a *TokenAuth) Authenticate(req *http.Request) error {
+	scheme := a.Scheme
+	if scheme == "" {
+		scheme = "Bearer"
+	}
+	req.Header.Set("Authorization", scheme+" "+a.Token)
+	return nil
+}
+
+// SetCredentials makes the client use basic authentication with the given
+// user and password.
+func (c *Client) SetCredentials(user string, passwd string) {
+	c.SetAuthProvider(&BasicAuth{Username: user, Password: passwd})
+}
+
+// SetAuthProvider replaces the authentication used by the client. A nil
+// provider sends unauthenticated requests.
+func (c *Client) SetAuthProvider(auth AuthProvider) {
+	c.mu.Lock()
+	c.auth = auth
+	c.mu.Unlock()
+}
+
+func (c *Client) authProvider() AuthProvider {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.auth
+}
+
+// authenticate returns a copy of the request authenticated by the auth
+// provider of the client.
+func (c *Client) authenticate(req *http.Request) (*http.Request, error) {
+	auth := c.authProvider()
+	if auth == nil {
+		return req, nil
+	}
+
+	r := req.Clone(req.Context())
+	if err := auth.Authenticate(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// WithAuthProvider sets the authentication used by the client.
+func WithAuthProvider(auth AuthProvider) ClientOption {
+	return func(c *Client) error {
+		c.SetAuthProvider(auth)
+		return nil
+	}
+}
@@ -0,0 +1,199 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BindPayload is what the yum distributor hands to a bound consumer to
+// access the published repository.
+type BindPayload struct {
+	RepoName     string            `json:"repo_name"`
+	ServerName   string            `json:"server_name"`
+	RelativePath string            `json:"relative_path"`
+	Protocols    []string          `json:"protocols"`
+	CaCert       string            `json:"ca_cert"`
+	ClientCert   string            `json:"client_cert"`
+	GpgKeys      map[string]string `json:"gpg_keys"`
+}
+
+func (p BindPayload) String() string {
+	return Stringify(p)
+}
+
+// Payload decodes the details of the binding, which hold the bind payload
+// of the distributor.
+func (b *Binding) Payload() (*BindPayload, error) {
+	p := new(BindPayload)
+	if err := decodeConfig(b.Details, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// URLs returns the urls of the published repository, one per protocol.
+func (p *BindPayload) URLs() []string {
+	path := "/" + strings.TrimPrefix(p.RelativePath, "/")
+
+	var urls []string
+	for _, protocol := range p.Protocols {
+		urls = append(urls, fmt.Sprintf("%s://%s%s", protocol, p.ServerName, path))
+	}
+	return urls
+}
+
+// YumRepo is a repository section of a yum .repo file.
+type YumRepo struct {
+	Id            string
+	Name          string
+	BaseURLs      []string
+	MirrorList    string
+	Enabled       bool
+	GpgCheck      bool
+	GpgKeys       []string
+	SslVerify     bool
+	SslCaCert     string
+	SslClientCert string
+	SslClientKey  string
+}
+
+// WriteTo writes the repository in the .repo file format.
+func (r *YumRepo) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s]\n", r.Id)
+	fmt.Fprintf(&b, "name=%s\n", r.Name)
+	if len(r.BaseURLs) > 0 {
+		fmt.Fprintf(&b, "baseurl=%s\n", strings.Join(r.BaseURLs, "\n        "))
+	}
+	if r.MirrorList != "" {
+		fmt.Fprintf(&b, "mirrorlist=%s\n", r.MirrorList)
+	}
+	fmt.Fprintf(&b, "enabled=%d\n", boolInt(r.Enabled))
+	fmt.Fprintf(&b, "gpgcheck=%d\n", boolInt(r.GpgCheck))
+	if len(r.GpgKeys) > 0 {
+		fmt.Fprintf(&b, "gpgkey=%s\n", strings.Join(r.GpgKeys, "\n       "))
+	}
+	fmt.Fprintf(&b, "sslverify=%d\n", boolInt(r.SslVerify))
+	if r.SslCaCert != "" {
+		fmt.Fprintf(&b, "sslcacert=%s\n", r.SslCaCert)
+	}
+	if r.SslClientCert != "" {
+		fmt.Fprintf(&b, "sslclientcert=%s\n", r.SslClientCert)
+	}
+	if r.SslClientKey != "" {
+		fmt.Fprintf(&b, "sslclientkey=%s\n", r.SslClientKey)
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// YumRepo returns the .repo section of the bound repository and the files
+// it refers to, the certificates and gpg keys, by path in certDir. The
+// server certificate is verified against the CA certificate of the payload
+// if any, against the system ones otherwise.
+func (p *BindPayload) YumRepo(id string, certDir string) (*YumRepo, map[string]string) {
+	r := &YumRepo{
+		Id:        id,
+		Name:      p.RepoName,
+		BaseURLs:  p.URLs(),
+		Enabled:   true,
+		SslVerify: true,
+	}
+	if r.Name == "" {
+		r.Name = id
+	}
+
+	files := make(map[string]string)
+
+	if p.CaCert != "" {
+		r.SslCaCert = filepath.Join(certDir, id+".ca.crt")
+		files[r.SslCaCert] = p.CaCert
+	}
+
+	// the entitlement certificate holds the key too
+	if p.ClientCert != "" {
+		r.SslClientCert = filepath.Join(certDir, id+".crt")
+		r.SslClientKey = r.SslClientCert
+		files[r.SslClientCert] = p.ClientCert
+	}
+
+	names := make([]string, 0, len(p.GpgKeys))
+	for name := range p.GpgKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(certDir, id+"-"+filepath.Base(name))
+		files[path] = p.GpgKeys[name]
+		r.GpgKeys = append(r.GpgKeys, "file://"+path)
+	}
+	r.GpgCheck = len(r.GpgKeys) > 0
+
+	return r, files
+}
+
+// WriteYumRepo writes the .repo file of the binding in repoDir, e.g.
+// /etc/yum.repos.d, and the certificates and gpg keys it refers to in
+// certDir. It returns the path of the .repo file.
+func (b *Binding) WriteYumRepo(repoDir string, certDir string) (string, error) {
+	p, err := b.Payload()
+	if err != nil {
+		return "", err
+	}
+	if len(p.Protocols) == 0 {
+		return "", fmt.Errorf("pulp: binding of %s has no published url", b.RepoId)
+	}
+
+	r, files := p.YumRepo(b.RepoId, certDir)
+
+	if len(files) > 0 {
+		if err := os.MkdirAll(certDir, 0755); err != nil {
+			return "", err
+		}
+	}
+	for path, content := range files {
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			return "", err
+		}
+	}
+
+	path := filepath.Join(repoDir, b.RepoId+".repo")
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if _, err := r.WriteTo(f); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
@@ -0,0 +1,276 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BulkError lists the resources for which a bulk operation failed.
+type BulkError struct {
+	Errors map[string]error
+}
+
+func (e *BulkError) Error() string {
+	ids := make([]string, 0, len(e.Errors))
+	for id := range e.Errors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	msgs := make([]string, len(ids))
+	for i, id := range ids {
+		msgs[i] = fmt.Sprintf("%s: %v", id, e.Errors[id])
+	}
+	return fmt.Sprintf("%d failed: %s", len(ids), strings.Join(msgs, "; "))
+}
+
+// SyncReport is the outcome of the sync of a repository.
+type SyncReport struct {
+	RepoId   string
+	Tasks    []*Task
+	Duration time.Duration
+	Err      error
+}
+
+func (r SyncReport) String() string {
+	return Stringify(r)
+}
+
+// SyncProgressFunc is called each time a task of a repository sync changes
+// state. It is called from several goroutines.
+type SyncProgressFunc func(repository string, task *Task)
+
+// SyncAll syncs the repositories, running at most concurrency syncs at a
+// time, and waits for all of them to finish. The reports are returned in
+// the order of the repositories; the error is a *BulkError listing the
+// failed syncs.
+func (s *RepositoriesService) SyncAll(repositories []string, concurrency int, progress SyncProgressFunc) ([]*SyncReport, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	reports := make([]*SyncReport, len(repositories))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, repo := range repositories {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, repo string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			reports[i] = s.syncAndWait(repo, progress)
+		}(i, repo)
+	}
+	wg.Wait()
+
+	errs := make(map[string]error)
+	for _, r := range reports {
+		if r.Err != nil {
+			errs[r.RepoId] = r.Err
+		}
+	}
+	if len(errs) > 0 {
+		return reports, &BulkError{Errors: errs}
+	}
+	return reports, nil
+}
+
+func (s *RepositoriesService) syncAndWait(repository string, progress SyncProgressFunc) *SyncReport {
+	r := &SyncReport{RepoId: repository}
+	start := time.Now()
+	defer func() {
+		r.Duration = time.Since(start)
+	}()
+
+	cr, _, err := s.SyncRepository(repository)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+
+	for _, st := range cr.SpawnedTasks {
+		var onChange func(*Task)
+		if progress != nil {
+			onChange = onStateChange(func(t *Task) {
+				progress(repository, t)
+			})
+		}
+
+		t, err := s.client.Tasks.waitForTask(st.TaskId, nil, onChange)
+		if t != nil {
+			r.Tasks = append(r.Tasks, t)
+		}
+		if err != nil {
+			r.Err = err
+			return r
+		}
+	}
+	return r
+}
+
+// DistributorSelector picks the distributors to publish with.
+type DistributorSelector func(d *Distributor) bool
+
+// DistributorTypeSelector picks the distributors of the types.
+func DistributorTypeSelector(types ...string) DistributorSelector {
+	return func(d *Distributor) bool {
+		for _, t := range types {
+			if d.DistributorTypeId == t {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// DistributorIdSelector picks the distributors with the ids.
+func DistributorIdSelector(ids ...string) DistributorSelector {
+	return func(d *Distributor) bool {
+		for _, id := range ids {
+			if d.Id == id {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// PublishReport is the outcome of the publish of a repository.
+type PublishReport struct {
+	RepoId   string
+	Tasks    []*Task
+	Duration time.Duration
+	Err      error
+}
+
+func (r PublishReport) String() string {
+	return Stringify(r)
+}
+
+// PublishAll publishes the repositories with their distributors picked by
+// the selector, all if nil, running at most concurrency repositories at a
+// time, and waits for all of them to finish. The reports are returned in
+// the order of the repositories; the error is a *BulkError listing the
+// failed publishes.
+func (s *RepositoriesService) PublishAll(repositories []string, selector DistributorSelector, concurrency int) ([]*PublishReport, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	reports := make([]*PublishReport, len(repositories))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, repo := range repositories {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, repo string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			start := time.Now()
+			tasks, err := s.publishAndWait(repo, selector, nil)
+			reports[i] = &PublishReport{RepoId: repo, Tasks: tasks, Duration: time.Since(start), Err: err}
+		}(i, repo)
+	}
+	wg.Wait()
+
+	errs := make(map[string]error)
+	for _, r := range reports {
+		if r.Err != nil {
+			errs[r.RepoId] = r.Err
+		}
+	}
+	if len(errs) > 0 {
+		return reports, &BulkError{Errors: errs}
+	}
+	return reports, nil
+}
+
+// BatchHydrate fetches the importers and distributors of the repositories
+// listed without them, running at most concurrency repositories at a time.
+// Repositories already holding importers or distributors are not fetched
+// again. The error is a *BulkError listing the failed repositories.
+func (s *RepositoriesService) BatchHydrate(repos []*Repository, concurrency int) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	errs := make(map[string]error)
+	var mu sync.Mutex
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for _, repo := range repos {
+		if repo.Importers != nil && repo.Distributors != nil {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(repo *Repository) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := s.hydrate(repo); err != nil {
+				mu.Lock()
+				errs[repo.Id] = err
+				mu.Unlock()
+			}
+		}(repo)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return &BulkError{Errors: errs}
+	}
+	return nil
+}
+
+func (s *RepositoriesService) hydrate(repo *Repository) error {
+	if repo.Importers == nil {
+		i, _, err := s.ListImporters(repo.Id)
+		if err != nil {
+			return err
+		}
+		repo.Importers = append([]*Importer{}, i...)
+	}
+
+	if repo.Distributors == nil {
+		d, _, err := s.ListDistributors(repo.Id)
+		if err != nil {
+			return err
+		}
+		repo.Distributors = append([]*Distributor{}, d...)
+	}
+	return nil
+}
@@ -0,0 +1,283 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// ResponseCache is a http.RoundTripper caching the responses of GET
+// requests carrying an ETag or a Last-Modified header. A cached url is
+// revalidated with a conditional request and the cached body is served when
+// the server answers 304 Not Modified, which keeps frequent polling of the
+// status or the repositories cheap for the server. The content requests of
+// the client, like downloads, and the streamed responses are not cached.
+type ResponseCache struct {
+	// Transport sends the requests, http.DefaultTransport if nil.
+	Transport http.RoundTripper
+
+	// MaxEntries is the number of cached responses, the least recently
+	// used one is evicted first. Zero means no limit.
+	MaxEntries int
+
+	// MaxBodySize is the size of the largest cached body, larger
+	// responses are passed through. Zero means DefaultMaxCachedBodySize.
+	MaxBodySize int64
+
+	once  sync.Once
+	store *cacheStore
+}
+
+// DefaultMaxCachedBodySize is the default size of the largest body cached
+// by a ResponseCache.
+const DefaultMaxCachedBodySize = 1 << 20
+
+// cacheStore holds the cached responses, it is kept when the client
+// rebuilds its transport.
+type cacheStore struct {
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	url          string
+	etag         string
+	lastModified string
+	status       int
+	header       http.Header
+	body         []byte
+}
+
+func NewResponseCache(transport http.RoundTripper, maxEntries int) *ResponseCache {
+	return &ResponseCache{
+		Transport:  transport,
+		MaxEntries: maxEntries,
+	}
+}
+
+// WithResponseCache caches the GET api responses of the client, keeping up
+// to maxEntries of them.
+func WithResponseCache(maxEntries int) ClientOption {
+	return func(c *Client) error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.cache = &ResponseCache{MaxEntries: maxEntries}
+		c.rebuild()
+		return nil
+	}
+}
+
+// withTransport returns a cache sending its requests with transport and
+// sharing the responses cached by rc.
+func (rc *ResponseCache) withTransport(transport http.RoundTripper) *ResponseCache {
+	return &ResponseCache{
+		Transport:   transport,
+		MaxEntries:  rc.MaxEntries,
+		MaxBodySize: rc.MaxBodySize,
+		store:       rc.getStore(),
+	}
+}
+
+type noCacheKey struct{}
+
+// withoutCache returns the request marked to not be cached by a
+// ResponseCache.
+func withoutCache(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), noCacheKey{}, true))
+}
+
+func (rc *ResponseCache) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != "GET" || req.Header.Get("Range") != "" || req.Context().Value(noCacheKey{}) != nil {
+		return rc.transport().RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	e := rc.get(key)
+	if e != nil {
+		// the request must not be modified by a RoundTripper
+		req = req.Clone(req.Context())
+		if e.etag != "" && req.Header.Get("If-None-Match") == "" {
+			req.Header.Set("If-None-Match", e.etag)
+		}
+		if e.lastModified != "" && req.Header.Get("If-Modified-Since") == "" {
+			req.Header.Set("If-Modified-Since", e.lastModified)
+		}
+	}
+
+	resp, err := rc.transport().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && e != nil {
+		resp.Body.Close()
+		return e.response(req), nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	etag := resp.Header.Get("ETag")
+	lastModified := resp.Header.Get("Last-Modified")
+	maxBodySize := rc.maxBodySize()
+	if etag == "" && lastModified == "" || resp.ContentLength > maxBodySize {
+		rc.remove(key)
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBodySize+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	if int64(len(body)) > maxBodySize {
+		// too large, pass the rest of the body through
+		rc.remove(key)
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	rc.add(&cacheEntry{
+		url:          key,
+		etag:         etag,
+		lastModified: lastModified,
+		status:       resp.StatusCode,
+		header:       resp.Header.Clone(),
+		body:         body,
+	})
+	return resp, nil
+}
+
+// Clear removes all the cached responses.
+func (rc *ResponseCache) Clear() {
+	st := rc.getStore()
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.lru = nil
+	st.entries = nil
+}
+
+// Len returns the number of cached responses.
+func (rc *ResponseCache) Len() int {
+	st := rc.getStore()
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return len(st.entries)
+}
+
+func (rc *ResponseCache) transport() http.RoundTripper {
+	if rc.Transport != nil {
+		return rc.Transport
+	}
+	return http.DefaultTransport
+}
+
+func (rc *ResponseCache) maxBodySize() int64 {
+	if rc.MaxBodySize > 0 {
+		return rc.MaxBodySize
+	}
+	return DefaultMaxCachedBodySize
+}
+
+func (rc *ResponseCache) getStore() *cacheStore {
+	rc.once.Do(func() {
+		if rc.store == nil {
+			rc.store = new(cacheStore)
+		}
+	})
+	return rc.store
+}
+
+func (rc *ResponseCache) get(key string) *cacheEntry {
+	st := rc.getStore()
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	el, ok := st.entries[key]
+	if !ok {
+		return nil
+	}
+	st.lru.MoveToFront(el)
+	return el.Value.(*cacheEntry)
+}
+
+func (rc *ResponseCache) add(e *cacheEntry) {
+	st := rc.getStore()
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.entries == nil {
+		st.lru = list.New()
+		st.entries = make(map[string]*list.Element)
+	}
+
+	if el, ok := st.entries[e.url]; ok {
+		el.Value = e
+		st.lru.MoveToFront(el)
+		return
+	}
+	st.entries[e.url] = st.lru.PushFront(e)
+
+	for rc.MaxEntries > 0 && st.lru.Len() > rc.MaxEntries {
+		oldest := st.lru.Back()
+		st.lru.Remove(oldest)
+		delete(st.entries, oldest.Value.(*cacheEntry).url)
+	}
+}
+
+func (rc *ResponseCache) remove(key string) {
+	st := rc.getStore()
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if el, ok := st.entries[key]; ok {
+		st.lru.Remove(el)
+		delete(st.entries, key)
+	}
+}
+
+// response builds a response to req from the cached entry.
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.status, http.StatusText(e.status)),
+		StatusCode:    e.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,74 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"encoding/json"
+)
+
+// CallReport is returned with a 202 by all asynchronous operations, like
+// sync, publish, copy and delete. The work is done by the spawned tasks.
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/conventions/sync-v-async.html#call-report
+type CallReport struct {
+	Result       json.RawMessage `json:"result"`
+	Error        *Error          `json:"error"`
+	SpawnedTasks []SpawnedTask   `json:"spawned_tasks"`
+
+	// set by the operations spawning a group of tasks, see WaitForGroup
+	GroupId string `json:"group_id"`
+}
+
+type SpawnedTask struct {
+	Href   string `json:"_href"`
+	TaskId string `json:"task_id"`
+}
+
+func (cr CallReport) String() string {
+	return Stringify(cr)
+}
+
+// TaskIds returns the ids of the spawned tasks.
+func (cr *CallReport) TaskIds() []string {
+	var ids []string
+	for _, t := range cr.SpawnedTasks {
+		ids = append(ids, t.TaskId)
+	}
+	return ids
+}
+
+// WaitAll waits for all spawned tasks to reach a final state, one after the
+// other. It returns the tasks waited for, and stops at the first task which
+// fails or times out.
+func (cr *CallReport) WaitAll(client *Client, opt *PollOptions) ([]*Task, error) {
+	if cr.Error != nil {
+		return nil, cr.Error
+	}
+
+	var tasks []*Task
+	for _, st := range cr.SpawnedTasks {
+		t, err := client.Tasks.WaitForTask(st.TaskId, opt)
+		if t != nil {
+			tasks = append(tasks, t)
+		}
+		if err != nil {
+			return tasks, err
+		}
+	}
+	return tasks, nil
+}
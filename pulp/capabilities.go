@@ -0,0 +1,124 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ServerInfo is the version and the plugins of the pulp server, as found by
+// Detect.
+type ServerInfo struct {
+	Version      string
+	ContentTypes []string
+	Importers    []string
+	Distributors []string
+}
+
+func (i ServerInfo) String() string {
+	return Stringify(i)
+}
+
+func (i *ServerInfo) HasContentType(typeId string) bool {
+	return containsString(i.ContentTypes, typeId)
+}
+
+func (i *ServerInfo) HasImporter(typeId string) bool {
+	return containsString(i.Importers, typeId)
+}
+
+func (i *ServerInfo) HasDistributor(typeId string) bool {
+	return containsString(i.Distributors, typeId)
+}
+
+// serverInfo holds what Detect found. It is shared by a client and the
+// clients returned by its WithOptions.
+type serverInfo struct {
+	mu   sync.RWMutex
+	info *ServerInfo
+}
+
+func containsString(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Detect queries the version and the plugins of the server and records
+// them on the client, and the clients sharing its configuration through
+// WithOptions. Afterwards, the methods handling the content of a
+// plugin return ErrUnsupported if the plugin is not installed, instead of
+// an error from the server. Nothing is checked until Detect is called.
+func (c *Client) Detect() (*ServerInfo, error) {
+	st, _, err := c.Status.GetStatus()
+	if err != nil {
+		return nil, err
+	}
+	info := &ServerInfo{Version: st.Versions.PlatformVersion}
+
+	types, _, err := c.Status.ListContentTypes()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range types {
+		info.ContentTypes = append(info.ContentTypes, t.Id)
+	}
+
+	importers, _, err := c.Status.ListImporterPlugins()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range importers {
+		info.Importers = append(info.Importers, p.Id)
+	}
+
+	distributors, _, err := c.Status.ListDistributorPlugins()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range distributors {
+		info.Distributors = append(info.Distributors, p.Id)
+	}
+
+	c.server.mu.Lock()
+	c.server.info = info
+	c.server.mu.Unlock()
+
+	return info, nil
+}
+
+// ServerInfo returns what Detect found, nil if it was not called.
+func (c *Client) ServerInfo() *ServerInfo {
+	c.server.mu.RLock()
+	defer c.server.mu.RUnlock()
+	return c.server.info
+}
+
+// requireContentType fails with ErrUnsupported if Detect found that the
+// server does not know the content type of the feature.
+func (c *Client) requireContentType(typeId string, plugin string) error {
+	info := c.ServerInfo()
+	if info == nil || info.HasContentType(typeId) {
+		return nil
+	}
+	return fmt.Errorf("%w: pulp %s has no %s content, install the %s plugin on the server",
+		ErrUnsupported, info.Version, typeId, plugin)
+}
@@ -0,0 +1,208 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// checksum types, pulp also names sha1 sha
+const (
+	ChecksumSha256 = "sha256"
+	ChecksumSha1   = "sha1"
+	ChecksumMd5    = "md5"
+)
+
+func newHash(checksumType string) (hash.Hash, error) {
+	switch normalizeChecksumType(checksumType) {
+	case ChecksumSha256:
+		return sha256.New(), nil
+	case ChecksumSha1:
+		return sha1.New(), nil
+	case ChecksumMd5:
+		return md5.New(), nil
+	}
+	return nil, fmt.Errorf("pulp: unsupported checksum type %q", checksumType)
+}
+
+func normalizeChecksumType(checksumType string) string {
+	t := strings.ToLower(checksumType)
+	if t == "sha" {
+		return ChecksumSha1
+	}
+	return t
+}
+
+// ChecksumReader computes the checksums of the content read through it.
+type ChecksumReader struct {
+	r      io.Reader
+	hashes map[string]hash.Hash
+	size   int64
+}
+
+// NewChecksumReader computes the checksums of the types, sha256 if none is
+// given.
+func NewChecksumReader(r io.Reader, checksumTypes ...string) (*ChecksumReader, error) {
+	if len(checksumTypes) == 0 {
+		checksumTypes = []string{ChecksumSha256}
+	}
+
+	c := &ChecksumReader{r: r, hashes: make(map[string]hash.Hash)}
+	for _, t := range checksumTypes {
+		h, err := newHash(t)
+		if err != nil {
+			return nil, err
+		}
+		c.hashes[normalizeChecksumType(t)] = h
+	}
+	return c, nil
+}
+
+func (c *ChecksumReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if n > 0 {
+		for _, h := range c.hashes {
+			h.Write(p[:n])
+		}
+		c.size += int64(n)
+	}
+	return n, err
+}
+
+// Size returns the number of bytes read so far.
+func (c *ChecksumReader) Size() int64 {
+	return c.size
+}
+
+// Sum returns the hex encoded checksum of the content read so far, or an
+// empty string if the type is not computed.
+func (c *ChecksumReader) Sum(checksumType string) string {
+	h, ok := c.hashes[normalizeChecksumType(checksumType)]
+	if !ok {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Sums returns the checksums of the content read so far by type.
+func (c *ChecksumReader) Sums() map[string]string {
+	sums := make(map[string]string, len(c.hashes))
+	for t := range c.hashes {
+		sums[t] = c.Sum(t)
+	}
+	return sums
+}
+
+// Verify compares the checksum of the content read so far, it returns an
+// error wrapping ErrChecksumMismatch if they differ.
+func (c *ChecksumReader) Verify(checksumType string, checksum string) error {
+	sum := c.Sum(checksumType)
+	if sum == "" {
+		return fmt.Errorf("pulp: %s checksum not computed", checksumType)
+	}
+	if !strings.EqualFold(sum, checksum) {
+		return fmt.Errorf("%w: %s is %s, expected %s", ErrChecksumMismatch, checksumType, sum, checksum)
+	}
+	return nil
+}
+
+// UnitChecksum returns the checksum of the file of the unit from its
+// metadata, or empty strings if it has none.
+func UnitChecksum(u *Unit) (checksumType string, checksum string) {
+	switch m := u.Metadata.(type) {
+	case *RpmUnit:
+		return m.ChecksumType, m.Checksum
+	case *IsoUnit:
+		return ChecksumSha256, m.Checksum
+	case *PuppetModuleUnit:
+		return m.ChecksumType, m.Checksum
+	case *DockerBlobUnit:
+		return splitDigest(m.Digest)
+	case *DockerManifestUnit:
+		return splitDigest(m.Digest)
+	}
+	return "", ""
+}
+
+// splitDigest splits a docker digest like sha256:e3b0...
+func splitDigest(digest string) (string, string) {
+	i := strings.Index(digest, ":")
+	if i < 0 {
+		return "", ""
+	}
+	return digest[:i], digest[i+1:]
+}
+
+// DownloadUnitFile writes the file of the unit served at fileUrl to w,
+// verifying it against the checksum of the unit metadata. A mismatch is
+// returned as an error wrapping ErrChecksumMismatch with StrictChecksums,
+// else it is only reported to the OnError hooks. With StrictChecksums a
+// unit without a checksum, or with a checksum type the client does not
+// support, is an error too; else the file is downloaded unverified.
+func (c *Client) DownloadUnitFile(u *Unit, fileUrl string, w io.Writer) (*Response, error) {
+	req, err := c.NewContentRequest("GET", fileUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	checksumType, checksum := UnitChecksum(u)
+	if checksum == "" {
+		if c.StrictChecksums {
+			return nil, fmt.Errorf("pulp: unit %s has no checksum to verify", u.UnitId)
+		}
+		return c.Download(req, w)
+	}
+
+	h, err := newHash(checksumType)
+	if err != nil {
+		if c.StrictChecksums {
+			return nil, err
+		}
+		c.onError(req, err)
+		return c.Download(req, w)
+	}
+
+	resp, err := c.Download(req, io.MultiWriter(w, h))
+	if err != nil {
+		return resp, err
+	}
+
+	if sum := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(sum, checksum) {
+		err := fmt.Errorf("%w: %s of %s is %s, expected %s", ErrChecksumMismatch, checksumType, fileUrl, sum, checksum)
+		if c.StrictChecksums {
+			return resp, err
+		}
+		c.onError(req, err)
+	}
+	return resp, nil
+}
+
+// WithStrictChecksums fails downloads of units which do not match, or
+// have no, checksum.
+func WithStrictChecksums() ClientOption {
+	return func(c *Client) error {
+		c.StrictChecksums = true
+		return nil
+	}
+}
@@ -0,0 +1,419 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp_test
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/msutter/go-pulp/pulp"
+	"github.com/msutter/go-pulp/pulptest"
+)
+
+type countingMetrics struct {
+	mu       sync.Mutex
+	requests int
+}
+
+func (m *countingMetrics) ObserveRequest(method, endpoint string, code int, elapsed time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests++
+}
+
+func (m *countingMetrics) ObserveError(method, endpoint string) {}
+
+func (m *countingMetrics) AddInFlightTasks(delta int) {}
+
+// TestConcurrentUse configures the client while service calls are in
+// flight, run it with -race.
+func TestConcurrentUse(t *testing.T) {
+	server := pulptest.NewServer()
+	defer server.Close()
+	server.AddRepository(&pulp.Repository{Id: "zoo"})
+
+	client, err := server.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if _, _, err := client.Repositories.GetRepository("zoo", nil); err != nil {
+					t.Error(err)
+					return
+				}
+				if _, _, err := client.Repositories.SyncRepository("zoo"); err != nil {
+					t.Error(err)
+					return
+				}
+				if _, _, err := client.Tasks.ListTasks(); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		client.SetTimeout(5000)
+		client.SetRetryPolicy(pulp.DefaultRetryPolicy())
+		client.SetRateLimit(1000, 10)
+		client.SetMetrics(&countingMetrics{})
+		client.Use(func(next http.RoundTripper) http.RoundTripper {
+			return next
+		})
+		if err := client.DisableSSLVerification(); err != nil {
+			t.Fatal(err)
+		}
+		client.WithOptions(&pulp.RequestOptions{Timeout: time.Second}).Tasks.ListTasks()
+	}
+
+	wg.Wait()
+}
+
+// TestWithHTTPClient checks that the http client given to the client is
+// left untouched.
+func TestWithHTTPClient(t *testing.T) {
+	server := pulptest.NewServer()
+	defer server.Close()
+
+	hc := &http.Client{}
+	client, err := server.Client(
+		pulp.WithHTTPClient(hc),
+		pulp.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+			return next
+		}),
+		pulp.WithInsecureSkipVerify(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := client.Repositories.ListRepositories(nil); err != nil {
+		t.Fatal(err)
+	}
+	if hc.Transport != nil {
+		t.Errorf("the transport of the http client was set to %T", hc.Transport)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	server := pulptest.NewServer()
+	defer server.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	server.HandleFunc("GET", "tasks/", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	})
+
+	client, err := server.Client(pulp.WithTimeout(50 * time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := client.Tasks.ListTasks(); !errors.Is(err, pulp.ErrTimeout) {
+		t.Errorf("ListTasks() error = %v, want ErrTimeout", err)
+	}
+}
+
+func TestGetErratumNotFound(t *testing.T) {
+	server := pulptest.NewServer()
+	defer server.Close()
+	server.AddRepository(&pulp.Repository{Id: "zoo"})
+
+	client, err := server.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := client.Units.GetErratum("zoo", "RHSA-2016:0176"); !errors.Is(err, pulp.ErrNotFound) {
+		t.Errorf("GetErratum() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestWaitForTaskContext(t *testing.T) {
+	server := pulptest.NewServer()
+	defer server.Close()
+	server.AddTask(&pulp.Task{Id: "t1", State: pulp.TaskRunning})
+
+	client, err := server.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.WithOptions(&pulp.RequestOptions{Context: ctx, Timeout: -1}).
+		Tasks.WaitForTask("t1", &pulp.PollOptions{Interval: time.Hour})
+	if !errors.Is(err, pulp.ErrTimeout) {
+		t.Errorf("WaitForTask() error = %v, want ErrTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("WaitForTask() returned after %v", elapsed)
+	}
+}
+
+func TestOAuthSignedPerAttempt(t *testing.T) {
+	server := pulptest.NewServer()
+	defer server.Close()
+
+	var mu sync.Mutex
+	var signatures []string
+	server.HandleFunc("GET", "tasks/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		signatures = append(signatures, r.Header.Get("Authorization"))
+		if len(signatures) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("[]"))
+	})
+
+	client, err := server.Client(
+		pulp.WithAuthProvider(&pulp.OAuth{Key: "key", Secret: "secret"}),
+		pulp.WithRetryPolicy(&pulp.RetryPolicy{MaxAttempts: 2}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := client.Tasks.ListTasks(); err != nil {
+		t.Fatal(err)
+	}
+	if len(signatures) != 2 || signatures[0] == "" || signatures[0] == signatures[1] {
+		t.Errorf("the attempts were sent with the signatures %q, want two different ones", signatures)
+	}
+}
+
+func TestDetectShared(t *testing.T) {
+	server := pulptest.NewServer()
+	defer server.Close()
+	server.Handle("GET", "status/", http.StatusOK, map[string]interface{}{
+		"versions": map[string]string{"platform_version": "2.8.7"},
+	})
+	server.Handle("GET", "plugins/types/", http.StatusOK, []*pulp.ContentType{{Id: "rpm"}})
+	server.Handle("GET", "plugins/importers/", http.StatusOK, []*pulp.Plugin{{Id: "yum_importer"}})
+	server.Handle("GET", "plugins/distributors/", http.StatusOK, []*pulp.Plugin{{Id: "yum_distributor"}})
+
+	client, err := server.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := client.WithOptions(&pulp.RequestOptions{Timeout: time.Minute})
+
+	if _, err := client.Detect(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := d.Units.ListErrata("zoo"); !errors.Is(err, pulp.ErrUnsupported) {
+		t.Errorf("ListErrata() error = %v, want ErrUnsupported", err)
+	}
+	if _, err := d.Docker.Registry("zoo"); !errors.Is(err, pulp.ErrUnsupported) {
+		t.Errorf("Registry() error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestSearchRepositoriesDetails(t *testing.T) {
+	server := pulptest.NewServer()
+	defer server.Close()
+	server.AddRepository(&pulp.Repository{Id: "zoo"})
+
+	client, err := server.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := client.Repositories.SearchRepositories(&pulp.SearchRepositoriesOptions{Details: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range server.Requests() {
+		if got := r.Query.Get("details"); got != "true" {
+			t.Errorf("%s %s was sent with details=%q, want true", r.Method, r.Path, got)
+		}
+	}
+}
+
+func TestUpdateDistributorNilOptions(t *testing.T) {
+	server := pulptest.NewServer()
+	defer server.Close()
+	server.HandleFunc("PUT", "repositories/zoo/distributors/yum_distributor/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"spawned_tasks":[]}`))
+	})
+
+	client, err := server.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := client.Repositories.UpdateDistributor("zoo", "yum_distributor", nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewClientCertTransport(t *testing.T) {
+	cert := tls.Certificate{Certificate: [][]byte{[]byte("cert")}}
+	tr := pulp.NewClientCertTransport(true, cert)
+
+	def := pulp.DefaultTransport()
+	if tr.Proxy == nil || tr.DialContext == nil || tr.TLSHandshakeTimeout != def.TLSHandshakeTimeout || tr.ResponseHeaderTimeout != def.ResponseHeaderTimeout || tr.MaxIdleConnsPerHost != def.MaxIdleConnsPerHost {
+		t.Errorf("NewClientCertTransport() does not use the default transport settings")
+	}
+	if !tr.TLSClientConfig.InsecureSkipVerify || len(tr.TLSClientConfig.Certificates) != 1 {
+		t.Errorf("NewClientCertTransport() tls config = %+v", tr.TLSClientConfig)
+	}
+}
+
+func TestImporterRoundTrip(t *testing.T) {
+	data := []byte(`{"id":"yum_importer","config":{"feed":"http://x/","proxy_host":"http://p","max_downloads":4}}`)
+
+	i := new(pulp.Importer)
+	if err := json.Unmarshal(data, i); err != nil {
+		t.Fatal(err)
+	}
+	i.ImporterConfig.Feed = "http://y/"
+
+	out, err := json.Marshal(i)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Config map[string]interface{} `json:"config"`
+	}
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"feed": "http://y/", "proxy_host": "http://p", "max_downloads": float64(4)}
+	if !reflect.DeepEqual(got.Config, want) {
+		t.Errorf("the importer config was encoded as %v, want %v", got.Config, want)
+	}
+}
+
+func TestRepositoryExtraFieldsRoundTrip(t *testing.T) {
+	data := []byte(`{"id":"zoo","scratchpad":{"checksum_type":"sha256"},
+		"importers":[{"id":"yum_importer","_ns":"repo_importers","config":{"feed":"http://x/"}}],
+		"distributors":[{"id":"yum_distributor","_ns":"repo_distributors","config":{"relative_url":"zoo"}}]}`)
+
+	r := new(pulp.Repository)
+	if err := json.Unmarshal(data, r); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Scratchpad   map[string]interface{}   `json:"scratchpad"`
+		Importers    []map[string]interface{} `json:"importers"`
+		Distributors []map[string]interface{} `json:"distributors"`
+	}
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Scratchpad["checksum_type"] != "sha256" {
+		t.Errorf("the repository was encoded without its scratchpad: %s", out)
+	}
+	if len(got.Importers) != 1 || got.Importers[0]["_ns"] != "repo_importers" {
+		t.Errorf("the importer was encoded without its _ns: %s", out)
+	}
+	if len(got.Distributors) != 1 || got.Distributors[0]["_ns"] != "repo_distributors" {
+		t.Errorf("the distributor was encoded without its _ns: %s", out)
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	server := pulptest.NewServer()
+	defer server.Close()
+
+	var mu sync.Mutex
+	revalidated := map[string]int{}
+	etagged := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				mu.Lock()
+				revalidated[r.URL.Path]++
+				mu.Unlock()
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Write([]byte(body))
+		}
+	}
+	server.HandleFunc("GET", "tasks/", etagged("[]"))
+	server.HandleFunc("GET", "repositories/", etagged(`[{"id":"zoo","description":"`+strings.Repeat("x", 1<<20)+`"}]`))
+	server.HandleFunc("GET", "/pulp/isos/zoo/a.iso", etagged("iso"))
+
+	client, err := server.Client(pulp.WithResponseCache(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := client.Tasks.ListTasks(); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := client.Repositories.ListRepositories(nil); err != nil {
+			t.Fatal(err)
+		}
+		req, err := client.NewContentRequest("GET", "/pulp/isos/zoo/a.iso")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Download(req, ioutil.Discard); err != nil {
+			t.Fatal(err)
+		}
+		// the cached responses are kept
+		client.Use(func(next http.RoundTripper) http.RoundTripper {
+			return next
+		})
+	}
+
+	if n := revalidated["/pulp/api/v2/tasks/"]; n != 1 {
+		t.Errorf("the tasks were revalidated %d times, want 1", n)
+	}
+	if n := revalidated["/pulp/api/v2/repositories/"]; n != 0 {
+		t.Errorf("the repositories larger than the max body size were revalidated %d times, want 0", n)
+	}
+	if n := revalidated["/pulp/isos/zoo/a.iso"]; n != 0 {
+		t.Errorf("the download was revalidated %d times, want 0", n)
+	}
+}
@@ -0,0 +1,134 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"strings"
+)
+
+type CloneOptions struct {
+	// defaults to the ones of the source repository
+	DisplayName string
+	Description string
+
+	// added to the notes of the source repository
+	Notes map[string]string
+
+	// copies only the units of these types, all by default
+	TypeIds []string
+
+	// publishes the clone with all its distributors
+	Publish bool
+
+	// polls the copy and publish tasks
+	Poll *PollOptions
+}
+
+// CloneRepository creates the repository destination with the importer and
+// distributors of source, and copies all the units of source into it. The
+// relative urls of the distributors are changed from the source to the
+// destination id, so both can be published.
+//
+// The destination repository is left in place if copying or publishing
+// fails.
+func (s *RepositoriesService) CloneRepository(source string, destination string, opt *CloneOptions) (*Repository, error) {
+	if opt == nil {
+		opt = &CloneOptions{}
+	}
+
+	src, _, err := s.GetRepository(source, &GetRepositoryOptions{Details: true})
+	if err != nil {
+		return nil, err
+	}
+
+	create := &CreateRepositoryOptions{
+		Id:          destination,
+		DisplayName: src.Name,
+		Description: src.Description,
+		Notes:       make(map[string]string),
+	}
+	if opt.DisplayName != "" {
+		create.DisplayName = opt.DisplayName
+	}
+	if opt.Description != "" {
+		create.Description = opt.Description
+	}
+	for k, v := range src.Notes {
+		create.Notes[k] = v
+	}
+	for k, v := range opt.Notes {
+		create.Notes[k] = v
+	}
+
+	if len(src.Importers) > 0 {
+		create.ImporterTypeId = src.Importers[0].ImporterTypeId
+		create.ImporterConfig = src.Importers[0].Config
+	}
+	for _, d := range src.Distributors {
+		create.Distributors = append(create.Distributors, &AddDistributorOptions{
+			DistributorId:     d.Id,
+			DistributorTypeId: d.DistributorTypeId,
+			DistributorConfig: cloneDistributorConfig(d.Config, source, destination),
+			AutoPublish:       d.AutoPublish,
+		})
+	}
+
+	repo, _, err := s.CreateRepository(create)
+	if err != nil {
+		return nil, err
+	}
+
+	cr, _, err := s.client.Units.CopyUnits(source, destination, NewUnitCriteria(opt.TypeIds...), nil)
+	if err != nil {
+		return repo, err
+	}
+	if _, err := cr.WaitAll(s.client, opt.Poll); err != nil {
+		return repo, err
+	}
+
+	if opt.Publish {
+		for _, d := range create.Distributors {
+			cr, _, err := s.PublishRepository(destination, &PublishOptions{Id: d.DistributorId})
+			if err != nil {
+				return repo, err
+			}
+			if _, err := cr.WaitAll(s.client, opt.Poll); err != nil {
+				return repo, err
+			}
+		}
+	}
+
+	return repo, nil
+}
+
+// cloneDistributorConfig copies the config, replacing the source id in the
+// relative url by the destination id.
+func cloneDistributorConfig(config map[string]interface{}, source string, destination string) map[string]interface{} {
+	c := make(map[string]interface{}, len(config))
+	for k, v := range config {
+		c[k] = v
+	}
+
+	if rel, ok := c["relative_url"].(string); ok {
+		if strings.Contains(rel, source) {
+			c["relative_url"] = strings.Replace(rel, source, destination, -1)
+		} else {
+			c["relative_url"] = destination
+		}
+	}
+	return c
+}
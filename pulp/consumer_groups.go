@@ -0,0 +1,210 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"fmt"
+)
+
+type ConsumerGroupsService struct {
+	client *Client
+}
+
+type ConsumerGroup struct {
+	Id          string                 `json:"id"`
+	DisplayName string                 `json:"display_name"`
+	Description string                 `json:"description"`
+	ConsumerIds []string               `json:"consumer_ids"`
+	Notes       map[string]interface{} `json:"notes"`
+	Href        string                 `json:"_href"`
+}
+
+func (g ConsumerGroup) String() string {
+	return Stringify(g)
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/consumer/group/retrieval.html
+func (s *ConsumerGroupsService) ListConsumerGroups() ([]*ConsumerGroup, *Response, error) {
+	req, err := s.client.NewRequest("GET", "consumer_groups/", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var g []*ConsumerGroup
+	resp, err := s.client.Do(req, &g)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return g, resp, err
+}
+
+func (s *ConsumerGroupsService) GetConsumerGroup(group string) (*ConsumerGroup, *Response, error) {
+	u := fmt.Sprintf("consumer_groups/%s/", group)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g := new(ConsumerGroup)
+	resp, err := s.client.Do(req, g)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return g, resp, err
+}
+
+type CreateConsumerGroupOptions struct {
+	Id          string                 `json:"id"`
+	DisplayName string                 `json:"display_name,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	ConsumerIds []string               `json:"consumer_ids,omitempty"`
+	Notes       map[string]interface{} `json:"notes,omitempty"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/consumer/group/cud.html
+func (s *ConsumerGroupsService) CreateConsumerGroup(opt *CreateConsumerGroupOptions) (*ConsumerGroup, *Response, error) {
+	req, err := s.client.NewRequest("POST", "consumer_groups/", opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g := new(ConsumerGroup)
+	resp, err := s.client.Do(req, g)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return g, resp, err
+}
+
+// only the non nil fields are updated, a nil note value removes the note
+type UpdateConsumerGroupOptions struct {
+	DisplayName *string                `json:"display_name,omitempty"`
+	Description *string                `json:"description,omitempty"`
+	Notes       map[string]interface{} `json:"notes,omitempty"`
+}
+
+func (s *ConsumerGroupsService) UpdateConsumerGroup(group string, opt *UpdateConsumerGroupOptions) (*ConsumerGroup, *Response, error) {
+	u := fmt.Sprintf("consumer_groups/%s/", group)
+
+	req, err := s.client.NewRequest("PUT", u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g := new(ConsumerGroup)
+	resp, err := s.client.Do(req, g)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return g, resp, err
+}
+
+func (s *ConsumerGroupsService) DeleteConsumerGroup(group string) (*Response, error) {
+	u := fmt.Sprintf("consumer_groups/%s/", group)
+
+	req, err := s.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
+
+// AssociateConsumers adds the consumers matching the criteria to the group,
+// e.g. NewCriteria().Where(In("id", "web1", "web2")), and returns the ids
+// of all the group members.
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/consumer/group/membership.html
+func (s *ConsumerGroupsService) AssociateConsumers(group string, criteria *Criteria) ([]string, *Response, error) {
+	return s.membership(group, "associate", criteria)
+}
+
+// UnassociateConsumers removes the consumers matching the criteria from the
+// group and returns the ids of the remaining group members.
+func (s *ConsumerGroupsService) UnassociateConsumers(group string, criteria *Criteria) ([]string, *Response, error) {
+	return s.membership(group, "unassociate", criteria)
+}
+
+func (s *ConsumerGroupsService) membership(group string, action string, criteria *Criteria) ([]string, *Response, error) {
+	u := fmt.Sprintf("consumer_groups/%s/actions/%s/", group, action)
+
+	if criteria == nil {
+		criteria = NewCriteria()
+	}
+
+	req, err := s.client.NewRequest("POST", u, &searchRequest{Criteria: criteria})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ids []string
+	resp, err := s.client.Do(req, &ids)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return ids, resp, err
+}
+
+// Bind binds all the consumers of the group, a task is spawned for each
+// consumer.
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/consumer/group/bind.html
+func (s *ConsumerGroupsService) Bind(group string, opt *BindOptions) (*CallReport, *Response, error) {
+	u := fmt.Sprintf("consumer_groups/%s/bindings/", group)
+	return s.client.Consumers.consumerAction("POST", u, opt)
+}
+
+func (s *ConsumerGroupsService) Unbind(group string, repository string, distributor string) (*CallReport, *Response, error) {
+	u := fmt.Sprintf("consumer_groups/%s/bindings/%s/%s/", group, repository, distributor)
+	return s.client.Consumers.consumerAction("DELETE", u, nil)
+}
+
+// InstallContent asks the agents of all the consumers of the group to
+// install the units, a task is spawned for each consumer.
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/consumer/group/content.html
+func (s *ConsumerGroupsService) InstallContent(group string, units []*ConsumerUnit, options interface{}) (*CallReport, *Response, error) {
+	return s.contentAction(group, "install", units, options)
+}
+
+func (s *ConsumerGroupsService) UpdateContent(group string, units []*ConsumerUnit, options interface{}) (*CallReport, *Response, error) {
+	return s.contentAction(group, "update", units, options)
+}
+
+func (s *ConsumerGroupsService) UninstallContent(group string, units []*ConsumerUnit, options interface{}) (*CallReport, *Response, error) {
+	return s.contentAction(group, "uninstall", units, options)
+}
+
+func (s *ConsumerGroupsService) contentAction(group string, action string, units []*ConsumerUnit, options interface{}) (*CallReport, *Response, error) {
+	u := fmt.Sprintf("consumer_groups/%s/actions/content/%s/", group, action)
+
+	if options == nil {
+		options = map[string]interface{}{}
+	}
+	return s.client.Consumers.consumerAction("POST", u, &consumerContentRequest{Units: units, Options: options})
+}
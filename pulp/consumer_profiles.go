@@ -0,0 +1,137 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Profile is the content installed on a consumer for a content type, e.g.
+// the rpm packages, used by pulp to compute the applicability of errata.
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/consumer/profile.html
+type Profile struct {
+	Id          string          `json:"_id"`
+	ConsumerId  string          `json:"consumer_id"`
+	ContentType string          `json:"content_type"`
+	Profile     json.RawMessage `json:"profile"`
+	ProfileHash string          `json:"profile_hash"`
+	Href        string          `json:"_href"`
+}
+
+func (p Profile) String() string {
+	return Stringify(p)
+}
+
+// RpmProfileEntry is an installed package of an rpm profile.
+type RpmProfileEntry struct {
+	Name    string `json:"name"`
+	Epoch   int    `json:"epoch"`
+	Version string `json:"version"`
+	Release string `json:"release"`
+	Arch    string `json:"arch"`
+	Vendor  string `json:"vendor"`
+}
+
+// RpmPackages decodes the packages of an rpm profile.
+func (p *Profile) RpmPackages() ([]*RpmProfileEntry, error) {
+	if p.ContentType != RpmUnitType {
+		return nil, fmt.Errorf("pulp: profile of %s is not an rpm profile", p.ContentType)
+	}
+
+	var entries []*RpmProfileEntry
+	if err := json.Unmarshal(p.Profile, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+type uploadProfileRequest struct {
+	ContentType string      `json:"content_type"`
+	Profile     interface{} `json:"profile"`
+}
+
+func (s *ConsumersService) ListProfiles(consumer string) ([]*Profile, *Response, error) {
+	u := fmt.Sprintf("consumers/%s/profiles/", consumer)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var p []*Profile
+	resp, err := s.client.Do(req, &p)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return p, resp, err
+}
+
+func (s *ConsumersService) GetProfile(consumer string, contentType string) (*Profile, *Response, error) {
+	u := fmt.Sprintf("consumers/%s/profiles/%s/", consumer, contentType)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := new(Profile)
+	resp, err := s.client.Do(req, p)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return p, resp, err
+}
+
+// UploadProfile creates or replaces the profile of the content type, e.g.
+// an []*RpmProfileEntry for RpmUnitType.
+func (s *ConsumersService) UploadProfile(consumer string, contentType string, profile interface{}) (*Profile, *Response, error) {
+	u := fmt.Sprintf("consumers/%s/profiles/", consumer)
+
+	req, err := s.client.NewRequest("POST", u, &uploadProfileRequest{ContentType: contentType, Profile: profile})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := new(Profile)
+	resp, err := s.client.Do(req, p)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return p, resp, err
+}
+
+// UploadRpmProfile uploads the installed packages of the consumer.
+func (s *ConsumersService) UploadRpmProfile(consumer string, packages []*RpmProfileEntry) (*Profile, *Response, error) {
+	return s.UploadProfile(consumer, RpmUnitType, packages)
+}
+
+func (s *ConsumersService) DeleteProfile(consumer string, contentType string) (*Response, error) {
+	u := fmt.Sprintf("consumers/%s/profiles/%s/", consumer, contentType)
+
+	req, err := s.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
@@ -0,0 +1,304 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"fmt"
+)
+
+// ConsumersService handles the consumers, the systems which are bound to
+// repositories and managed by pulp through their agent.
+type ConsumersService struct {
+	client *Client
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/consumer/cud.html
+type Consumer struct {
+	Id           string                 `json:"id"`
+	DisplayName  string                 `json:"display_name"`
+	Description  string                 `json:"description"`
+	Notes        map[string]interface{} `json:"notes"`
+	Capabilities map[string]interface{} `json:"capabilities"`
+	Bindings     []*Binding             `json:"bindings"`
+	Href         string                 `json:"_href"`
+}
+
+func (c Consumer) String() string {
+	return Stringify(c)
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/consumer/bind.html
+type Binding struct {
+	Id            string                 `json:"_id"`
+	ConsumerId    string                 `json:"consumer_id"`
+	RepoId        string                 `json:"repo_id"`
+	DistributorId string                 `json:"distributor_id"`
+	TypeId        string                 `json:"type_id"`
+	NotifyAgent   bool                   `json:"notify_agent"`
+	BindingConfig map[string]interface{} `json:"binding_config"`
+	Details       map[string]interface{} `json:"details"`
+	Deleted       bool                   `json:"deleted"`
+	Href          string                 `json:"_href"`
+}
+
+func (b Binding) String() string {
+	return Stringify(b)
+}
+
+// ConsumerUnit selects the content of a consumer action, e.g. an rpm by its
+// name, unit key {"name": "zsh"}.
+type ConsumerUnit struct {
+	TypeId  string      `json:"type_id"`
+	UnitKey interface{} `json:"unit_key"`
+}
+
+type ListConsumersOptions struct {
+	Details  bool `url:"details,omitempty" json:"details,omitempty"`
+	Bindings bool `url:"bindings,omitempty" json:"bindings,omitempty"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/consumer/retrieval.html
+func (s *ConsumersService) ListConsumers(opt *ListConsumersOptions) ([]*Consumer, *Response, error) {
+	req, err := s.client.NewRequest("GET", "consumers/", opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var c []*Consumer
+	resp, err := s.client.Do(req, &c)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return c, resp, err
+}
+
+func (s *ConsumersService) GetConsumer(consumer string, opt *ListConsumersOptions) (*Consumer, *Response, error) {
+	u := fmt.Sprintf("consumers/%s/", consumer)
+
+	req, err := s.client.NewRequest("GET", u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c := new(Consumer)
+	resp, err := s.client.Do(req, c)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return c, resp, err
+}
+
+type RegisterConsumerOptions struct {
+	Id          string                 `json:"id"`
+	DisplayName string                 `json:"display_name,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Notes       map[string]interface{} `json:"notes,omitempty"`
+	RsaPub      string                 `json:"rsa_pub,omitempty"`
+}
+
+// the certificate authenticates the agent of the consumer
+type ConsumerRegistration struct {
+	Consumer    *Consumer `json:"consumer"`
+	Certificate string    `json:"certificate"`
+}
+
+func (s *ConsumersService) RegisterConsumer(opt *RegisterConsumerOptions) (*ConsumerRegistration, *Response, error) {
+	req, err := s.client.NewRequest("POST", "consumers/", opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := new(ConsumerRegistration)
+	resp, err := s.client.Do(req, r)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return r, resp, err
+}
+
+// only the non nil fields are updated, a nil note value removes the note
+type UpdateConsumerOptions struct {
+	DisplayName *string                `json:"display_name,omitempty"`
+	Description *string                `json:"description,omitempty"`
+	Notes       map[string]interface{} `json:"notes,omitempty"`
+}
+
+type updateConsumerRequest struct {
+	Delta *UpdateConsumerOptions `json:"delta"`
+}
+
+func (s *ConsumersService) UpdateConsumer(consumer string, opt *UpdateConsumerOptions) (*Consumer, *Response, error) {
+	u := fmt.Sprintf("consumers/%s/", consumer)
+
+	req, err := s.client.NewRequest("PUT", u, &updateConsumerRequest{Delta: opt})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c := new(Consumer)
+	resp, err := s.client.Do(req, c)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return c, resp, err
+}
+
+func (s *ConsumersService) UnregisterConsumer(consumer string) (*Response, error) {
+	u := fmt.Sprintf("consumers/%s/", consumer)
+
+	req, err := s.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
+
+func (s *ConsumersService) ListBindings(consumer string) ([]*Binding, *Response, error) {
+	u := fmt.Sprintf("consumers/%s/bindings/", consumer)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var b []*Binding
+	resp, err := s.client.Do(req, &b)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return b, resp, err
+}
+
+type BindOptions struct {
+	RepoId        string      `json:"repo_id"`
+	DistributorId string      `json:"distributor_id"`
+	NotifyAgent   *bool       `json:"notify_agent,omitempty"`
+	BindingConfig interface{} `json:"binding_config,omitempty"`
+}
+
+// the agent of the consumer is notified by a spawned task, unless
+// NotifyAgent is false
+func (s *ConsumersService) Bind(consumer string, opt *BindOptions) (*CallReport, *Response, error) {
+	u := fmt.Sprintf("consumers/%s/bindings/", consumer)
+	return s.consumerAction("POST", u, opt)
+}
+
+func (s *ConsumersService) Unbind(consumer string, repository string, distributor string) (*CallReport, *Response, error) {
+	u := fmt.Sprintf("consumers/%s/bindings/%s/%s/", consumer, repository, distributor)
+	return s.consumerAction("DELETE", u, nil)
+}
+
+// options of the rpm content handler of the agent
+type ContentActionOptions struct {
+	// apply the action, false for a dry run
+	Apply *bool `json:"apply,omitempty"`
+
+	// import the gpg keys of the packages
+	ImportKeys bool `json:"importkeys,omitempty"`
+
+	// reboot the consumer after the action
+	Reboot bool `json:"reboot,omitempty"`
+
+	// update all the packages, ignoring the units
+	All bool `json:"all,omitempty"`
+}
+
+// PackageUnits selects rpms by name, or name-version-release.arch like
+// yum does.
+func PackageUnits(names ...string) []*ConsumerUnit {
+	var units []*ConsumerUnit
+	for _, n := range names {
+		units = append(units, &ConsumerUnit{
+			TypeId:  RpmUnitType,
+			UnitKey: map[string]string{"name": n},
+		})
+	}
+	return units
+}
+
+// ErratumUnits selects errata by id, e.g. RHSA-2016:0176.
+func ErratumUnits(ids ...string) []*ConsumerUnit {
+	var units []*ConsumerUnit
+	for _, id := range ids {
+		units = append(units, &ConsumerUnit{
+			TypeId:  ErratumUnitType,
+			UnitKey: map[string]string{"id": id},
+		})
+	}
+	return units
+}
+
+// InstallContent asks the agent of the consumer to install the units. The
+// options are passed to the content handlers, e.g. a
+// *ContentActionOptions. The result of the spawned task holds the report
+// of the agent.
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/consumer/content.html#install-content-on-a-consumer
+func (s *ConsumersService) InstallContent(consumer string, units []*ConsumerUnit, options interface{}) (*CallReport, *Response, error) {
+	return s.contentAction(consumer, "install", units, options)
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/consumer/content.html#update-content-on-a-consumer
+func (s *ConsumersService) UpdateContent(consumer string, units []*ConsumerUnit, options interface{}) (*CallReport, *Response, error) {
+	return s.contentAction(consumer, "update", units, options)
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/consumer/content.html#uninstall-content-on-a-consumer
+func (s *ConsumersService) UninstallContent(consumer string, units []*ConsumerUnit, options interface{}) (*CallReport, *Response, error) {
+	return s.contentAction(consumer, "uninstall", units, options)
+}
+
+type consumerContentRequest struct {
+	Units   []*ConsumerUnit `json:"units"`
+	Options interface{}     `json:"options"`
+}
+
+func (s *ConsumersService) contentAction(consumer string, action string, units []*ConsumerUnit, options interface{}) (*CallReport, *Response, error) {
+	u := fmt.Sprintf("consumers/%s/actions/content/%s/", consumer, action)
+
+	if options == nil {
+		options = map[string]interface{}{}
+	}
+	return s.consumerAction("POST", u, &consumerContentRequest{Units: units, Options: options})
+}
+
+func (s *ConsumersService) consumerAction(method string, u string, opt interface{}) (*CallReport, *Response, error) {
+	req, err := s.client.NewRequest(method, u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cr := new(CallReport)
+	resp, err := s.client.Do(req, cr)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return cr, resp, err
+}
@@ -0,0 +1,87 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type ContentService struct {
+	client *Client
+}
+
+// ContentUnit is a unit as stored by pulp, independent of any repository.
+// Metadata is decoded like the metadata of a Unit.
+type ContentUnit struct {
+	Id                    string          `json:"_id"`
+	TypeId                string          `json:"_content_type_id"`
+	Href                  string          `json:"_href"`
+	RepositoryMemberships []string        `json:"repository_memberships"`
+	Metadata              interface{}     `json:"-"`
+	RawMetadata           json.RawMessage `json:"-"`
+}
+
+func (u ContentUnit) String() string {
+	return Stringify(u)
+}
+
+func (u *ContentUnit) UnmarshalJSON(data []byte) error {
+	type contentUnit ContentUnit
+	if err := json.Unmarshal(data, (*contentUnit)(u)); err != nil {
+		return err
+	}
+
+	u.RawMetadata = append(json.RawMessage(nil), data...)
+
+	var err error
+	u.Metadata, err = DecodeUnitMetadata(u.TypeId, u.RawMetadata)
+	return err
+}
+
+type contentSearchRequest struct {
+	Criteria     *Criteria `json:"criteria"`
+	IncludeRepos bool      `json:"include_repos"`
+}
+
+// SearchUnits searches the units of a content type across all
+// repositories. The ids of the repositories containing each unit are
+// returned in RepositoryMemberships.
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/content/retrieval.html#search-for-units
+func (s *ContentService) SearchUnits(typeId string, criteria *Criteria) ([]*ContentUnit, *Response, error) {
+	u := fmt.Sprintf("content/units/%s/search/", typeId)
+
+	if criteria == nil {
+		criteria = &Criteria{}
+	}
+	opt := &contentSearchRequest{Criteria: criteria, IncludeRepos: true}
+
+	req, err := s.client.NewRequestWithQuery("POST", u, nil, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var units []*ContentUnit
+	resp, err := s.client.Do(req, &units)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return units, resp, err
+}
@@ -0,0 +1,129 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"fmt"
+)
+
+// ContentSourcesService handles the alternate content sources configured on
+// the pulp server, which are used to download content during a sync.
+type ContentSourcesService struct {
+	client *Client
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/content/sources.html
+type ContentSource struct {
+	SourceId      string `json:"source_id"`
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	BaseUrl       string `json:"base_url"`
+	Paths         string `json:"paths"`
+	Priority      string `json:"priority"`
+	Expires       string `json:"expires"`
+	MaxConcurrent string `json:"max_concurrent"`
+	Enabled       string `json:"enabled"`
+	SslValidation string `json:"ssl_validation"`
+	Href          string `json:"_href"`
+}
+
+func (c ContentSource) String() string {
+	return Stringify(c)
+}
+
+func (s *ContentSourcesService) ListContentSources() ([]*ContentSource, *Response, error) {
+	req, err := s.client.NewRequest("GET", "content/sources/", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var c []*ContentSource
+	resp, err := s.client.Do(req, &c)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return c, resp, err
+}
+
+func (s *ContentSourcesService) GetContentSource(source string) (*ContentSource, *Response, error) {
+	u := fmt.Sprintf("content/sources/%s/", source)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c := new(ContentSource)
+	resp, err := s.client.Do(req, c)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return c, resp, err
+}
+
+// the catalog of all content sources is refreshed by a spawned task
+func (s *ContentSourcesService) RefreshContentSources() (*CallReport, *Response, error) {
+	return s.refresh("content/sources/action/refresh/")
+}
+
+// the catalog of the content source is refreshed by a spawned task
+func (s *ContentSourcesService) RefreshContentSource(source string) (*CallReport, *Response, error) {
+	u := fmt.Sprintf("content/sources/%s/action/refresh/", source)
+	return s.refresh(u)
+}
+
+func (s *ContentSourcesService) refresh(u string) (*CallReport, *Response, error) {
+	req, err := s.client.NewRequest("POST", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cr := new(CallReport)
+	resp, err := s.client.Do(req, cr)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return cr, resp, err
+}
+
+// DeleteCatalogEntries deletes the entries contributed by the content
+// source to the content catalog, and returns how many were deleted.
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/content/catalog.html
+func (s *ContentSourcesService) DeleteCatalogEntries(source string) (int, *Response, error) {
+	u := fmt.Sprintf("content/catalog/%s/", source)
+
+	req, err := s.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var r struct {
+		Deleted int `json:"deleted"`
+	}
+	resp, err := s.client.Do(req, &r)
+	if err != nil {
+		return 0, resp, err
+	}
+
+	return r.Deleted, resp, err
+}
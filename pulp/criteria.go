@@ -0,0 +1,304 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	SortAscending  = "ascending"
+	SortDescending = "descending"
+)
+
+// Criteria are used by all pulp searches. They can be built fluently:
+//
+//	c := pulp.NewCriteria().
+//		Where(pulp.Eq("name", "kernel"), pulp.Gte("version", "3.10")).
+//		OrderBy("version", pulp.SortDescending).
+//		SetLimit(10)
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/conventions/criteria.html
+type Criteria struct {
+	Filters Filter      `json:"filters,omitempty"`
+	Sort    []SortField `json:"sort,omitempty"`
+	Limit   int         `json:"limit,omitempty"`
+	Skip    int         `json:"skip,omitempty"`
+	Fields  []string    `json:"fields,omitempty"`
+}
+
+func NewCriteria() *Criteria {
+	return &Criteria{}
+}
+
+// Where adds filters to the criteria, all of them have to match.
+func (c *Criteria) Where(filters ...Filter) *Criteria {
+	c.Filters = mergeFilters(c.Filters, filters...)
+	return c
+}
+
+func (c *Criteria) OrderBy(field string, direction string) *Criteria {
+	c.Sort = append(c.Sort, SortField{Field: field, Direction: direction})
+	return c
+}
+
+// Select limits the returned documents to the given fields.
+func (c *Criteria) Select(fields ...string) *Criteria {
+	c.Fields = append(c.Fields, fields...)
+	return c
+}
+
+func (c *Criteria) SetLimit(limit int) *Criteria {
+	c.Limit = limit
+	return c
+}
+
+func (c *Criteria) SetSkip(skip int) *Criteria {
+	c.Skip = skip
+	return c
+}
+
+// UnitCriteria are the criteria of unit association searches, which filter,
+// sort and select the unit metadata and the association separately.
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/conventions/criteria.html#unit-association-criteria
+type UnitCriteria struct {
+	TypeIds []string    `json:"type_ids,omitempty"`
+	Filters *UnitFilter `json:"filters,omitempty"`
+	Sort    *UnitSort   `json:"sort,omitempty"`
+	Fields  *UnitFields `json:"fields,omitempty"`
+	Limit   int         `json:"limit,omitempty"`
+	Skip    int         `json:"skip,omitempty"`
+}
+
+type UnitFilter struct {
+	Unit        Filter `json:"unit,omitempty"`
+	Association Filter `json:"association,omitempty"`
+}
+
+type UnitSort struct {
+	Unit        []SortField `json:"unit,omitempty"`
+	Association []SortField `json:"association,omitempty"`
+}
+
+type UnitFields struct {
+	Unit        []string `json:"unit,omitempty"`
+	Association []string `json:"association,omitempty"`
+}
+
+func NewUnitCriteria(typeIds ...string) *UnitCriteria {
+	return &UnitCriteria{TypeIds: typeIds}
+}
+
+// WhereUnit adds filters on the unit metadata.
+func (c *UnitCriteria) WhereUnit(filters ...Filter) *UnitCriteria {
+	if c.Filters == nil {
+		c.Filters = &UnitFilter{}
+	}
+	c.Filters.Unit = mergeFilters(c.Filters.Unit, filters...)
+	return c
+}
+
+// WhereAssociation adds filters on the association fields, like created.
+func (c *UnitCriteria) WhereAssociation(filters ...Filter) *UnitCriteria {
+	if c.Filters == nil {
+		c.Filters = &UnitFilter{}
+	}
+	c.Filters.Association = mergeFilters(c.Filters.Association, filters...)
+	return c
+}
+
+// CreatedBetween selects the units associated with the repository at or
+// after from and before to. A zero time leaves that end of the range open.
+func (c *UnitCriteria) CreatedBetween(from time.Time, to time.Time) *UnitCriteria {
+	if !from.IsZero() {
+		c.WhereAssociation(Gte("created", criteriaTime(from)))
+	}
+	if !to.IsZero() {
+		c.WhereAssociation(Lt("created", criteriaTime(to)))
+	}
+	return c
+}
+
+func (c *UnitCriteria) OrderUnitsBy(field string, direction string) *UnitCriteria {
+	if c.Sort == nil {
+		c.Sort = &UnitSort{}
+	}
+	c.Sort.Unit = append(c.Sort.Unit, SortField{Field: field, Direction: direction})
+	return c
+}
+
+func (c *UnitCriteria) OrderAssociationsBy(field string, direction string) *UnitCriteria {
+	if c.Sort == nil {
+		c.Sort = &UnitSort{}
+	}
+	c.Sort.Association = append(c.Sort.Association, SortField{Field: field, Direction: direction})
+	return c
+}
+
+// SelectUnitFields limits the returned unit metadata to the given fields.
+func (c *UnitCriteria) SelectUnitFields(fields ...string) *UnitCriteria {
+	if c.Fields == nil {
+		c.Fields = &UnitFields{}
+	}
+	c.Fields.Unit = append(c.Fields.Unit, fields...)
+	return c
+}
+
+func (c *UnitCriteria) SetLimit(limit int) *UnitCriteria {
+	c.Limit = limit
+	return c
+}
+
+func (c *UnitCriteria) SetSkip(skip int) *UnitCriteria {
+	c.Skip = skip
+	return c
+}
+
+// Filter is a mongo style filter document.
+type Filter map[string]interface{}
+
+func Eq(field string, value interface{}) Filter {
+	return Filter{field: value}
+}
+
+func Ne(field string, value interface{}) Filter {
+	return Filter{field: Filter{"$ne": value}}
+}
+
+func Gt(field string, value interface{}) Filter {
+	return Filter{field: Filter{"$gt": value}}
+}
+
+func Gte(field string, value interface{}) Filter {
+	return Filter{field: Filter{"$gte": value}}
+}
+
+func Lt(field string, value interface{}) Filter {
+	return Filter{field: Filter{"$lt": value}}
+}
+
+func Lte(field string, value interface{}) Filter {
+	return Filter{field: Filter{"$lte": value}}
+}
+
+// In matches when the field is one of the given values. Pass the values of
+// a slice with In("id", pulp.Strings(ids)...).
+func In(field string, values ...interface{}) Filter {
+	return Filter{field: Filter{"$in": values}}
+}
+
+func Nin(field string, values ...interface{}) Filter {
+	return Filter{field: Filter{"$nin": values}}
+}
+
+// All matches array fields containing all of the given values.
+func All(field string, values ...interface{}) Filter {
+	return Filter{field: Filter{"$all": values}}
+}
+
+func Regex(field string, pattern string) Filter {
+	return Filter{field: Filter{"$regex": pattern}}
+}
+
+func Exists(field string, exists bool) Filter {
+	return Filter{field: Filter{"$exists": exists}}
+}
+
+func And(filters ...Filter) Filter {
+	return Filter{"$and": filters}
+}
+
+func Or(filters ...Filter) Filter {
+	return Filter{"$or": filters}
+}
+
+// Strings converts a string slice for use with In, Nin and All.
+func Strings(values []string) []interface{} {
+	v := make([]interface{}, len(values))
+	for i := range values {
+		v[i] = values[i]
+	}
+	return v
+}
+
+// mergeFilters combines the filters in a single document, falling back on
+// $and when several filters apply to the same field.
+func mergeFilters(base Filter, filters ...Filter) Filter {
+	var all []Filter
+	if len(base) > 0 {
+		all = append(all, base)
+	}
+	for _, f := range filters {
+		if len(f) > 0 {
+			all = append(all, f)
+		}
+	}
+
+	m := Filter{}
+	for _, f := range all {
+		for k, v := range f {
+			if _, ok := m[k]; ok {
+				return And(all...)
+			}
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// SortField is encoded the way pulp expects it: ["field", "direction"]
+type SortField struct {
+	Field     string
+	Direction string
+}
+
+func (s SortField) MarshalJSON() ([]byte, error) {
+	d := s.Direction
+	if d == "" {
+		d = SortAscending
+	}
+	return json.Marshal([]string{s.Field, d})
+}
+
+func (s *SortField) UnmarshalJSON(data []byte) error {
+	var f []string
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	if len(f) > 0 {
+		s.Field = f[0]
+	}
+	if len(f) > 1 {
+		s.Direction = f[1]
+	}
+	return nil
+}
+
+// the date format used by pulp in criteria filters
+const criteriaTimeFormat = "2006-01-02T15:04:05Z"
+
+func criteriaTime(t time.Time) string {
+	return t.UTC().Format(criteriaTimeFormat)
+}
+
+type searchRequest struct {
+	Criteria *Criteria `json:"criteria"`
+}
@@ -0,0 +1,156 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"strings"
+)
+
+const (
+	DebUnitType        = "deb"
+	DebImporterType    = "deb_importer"
+	DebDistributorType = "deb_distributor"
+)
+
+func init() {
+	RegisterUnitType(DebUnitType, func() interface{} { return new(DebUnit) })
+}
+
+// Releases, Components and Architectures are comma separated lists, e.g.
+// "stretch,buster", "main,contrib" and "amd64".
+//
+// Pulp Api docs:
+// https://pulp-deb.readthedocs.io/en/latest/tech-reference/importer.html
+type DebImporterConfig struct {
+	Feed             string `json:"feed,omitempty"`
+	Releases         string `json:"releases,omitempty"`
+	Components       string `json:"components,omitempty"`
+	Architectures    string `json:"architectures,omitempty"`
+	RequireSignature *bool  `json:"require_signature,omitempty"`
+	GpgKeys          string `json:"gpg_keys,omitempty"`
+	AllowedKeys      string `json:"allowed_keys,omitempty"`
+	RemoveMissing    *bool  `json:"remove_missing,omitempty"`
+	SslCaCert        string `json:"ssl_ca_cert,omitempty"`
+	SslClientCert    string `json:"ssl_client_cert,omitempty"`
+	SslClientKey     string `json:"ssl_client_key,omitempty"`
+	SslValidation    *bool  `json:"ssl_validation,omitempty"`
+	ProxyHost        string `json:"proxy_host,omitempty"`
+	ProxyPort        int    `json:"proxy_port,omitempty"`
+	ProxyUsername    string `json:"proxy_username,omitempty"`
+	ProxyPassword    string `json:"proxy_password,omitempty"`
+}
+
+func (c DebImporterConfig) String() string {
+	return Stringify(c)
+}
+
+// Pulp Api docs:
+// https://pulp-deb.readthedocs.io/en/latest/tech-reference/distributor.html
+type DebDistributorConfig struct {
+	RelativeUrl string `json:"relative_url,omitempty"`
+	Http        bool   `json:"http"`
+	Https       bool   `json:"https"`
+}
+
+// DebUnit is a debian package. The relation fields, like Depends, hold the
+// relations as decoded from json: a list of alternatives or of
+// {"name", "version", "flag"} documents.
+//
+// Pulp Api docs:
+// https://pulp-deb.readthedocs.io/en/latest/tech-reference/deb.html
+type DebUnit struct {
+	UnitMetadata
+	Name          string        `json:"name"`
+	Version       string        `json:"version"`
+	Architecture  string        `json:"architecture"`
+	Checksum      string        `json:"checksum"`
+	ChecksumType  string        `json:"checksumtype"`
+	Filename      string        `json:"filename"`
+	RelativePath  string        `json:"relativepath"`
+	Size          int64         `json:"size"`
+	Source        string        `json:"source"`
+	Section       string        `json:"section"`
+	Priority      string        `json:"priority"`
+	Maintainer    string        `json:"maintainer"`
+	Description   string        `json:"description"`
+	InstalledSize string        `json:"installed_size"`
+	Depends       []interface{} `json:"depends"`
+	PreDepends    []interface{} `json:"pre_depends"`
+	Recommends    []interface{} `json:"recommends"`
+	Suggests      []interface{} `json:"suggests"`
+	Conflicts     []interface{} `json:"conflicts"`
+	Breaks        []interface{} `json:"breaks"`
+	Provides      []interface{} `json:"provides"`
+	Replaces      []interface{} `json:"replaces"`
+}
+
+func (u *Unit) Deb() *DebUnit {
+	m, _ := u.Metadata.(*DebUnit)
+	return m
+}
+
+// DebContentPath returns the path of a published debian repository.
+func DebContentPath(relativeUrl string) string {
+	return "/pulp/deb/" + strings.Trim(relativeUrl, "/") + "/"
+}
+
+// ListDebs returns the debian packages of the repository.
+func (s *UnitsService) ListDebs(repository string) ([]*DebUnit, *Response, error) {
+	if err := s.client.requireContentType(DebUnitType, "pulp_deb"); err != nil {
+		return nil, nil, err
+	}
+
+	units, resp, err := s.SearchUnits(repository, NewUnitCriteria(DebUnitType))
+	if err != nil {
+		return nil, resp, err
+	}
+
+	var debs []*DebUnit
+	for _, u := range units {
+		if d := u.Deb(); d != nil {
+			debs = append(debs, d)
+		}
+	}
+	return debs, resp, err
+}
+
+// DebSyncOptions limit a sync to some of the releases (distributions),
+// components and architectures of the feed. Empty lists keep the ones
+// configured on the importer.
+type DebSyncOptions struct {
+	Releases      []string
+	Components    []string
+	Architectures []string
+}
+
+// SyncDeb syncs the debian repository with the importer config overridden
+// by the options for this sync only.
+func (s *RepositoriesService) SyncDeb(repository string, opt *DebSyncOptions) (*CallReport, *Response, error) {
+	if err := s.client.requireContentType(DebUnitType, "pulp_deb"); err != nil {
+		return nil, nil, err
+	}
+
+	var config interface{}
+	if opt != nil {
+		config = &DebImporterConfig{
+			Releases:      strings.Join(opt.Releases, ","),
+			Components:    strings.Join(opt.Components, ","),
+			Architectures: strings.Join(opt.Architectures, ","),
+		}
+	}
+	return s.SyncRepositoryWithConfig(repository, config)
+}
@@ -0,0 +1,146 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ExportDefinition returns the configuration of the repository: its notes,
+// the config of its importer and distributors and their schedules, to
+// recreate it on another server with ImportDefinition. The definition
+// marshals to json. The secrets hidden by pulp, like proxy_password, are
+// left out and have to be added to the definition before importing it.
+func (s *RepositoriesService) ExportDefinition(repository string) (*RepoSpec, error) {
+	r, _, err := s.GetRepository(repository, &GetRepositoryOptions{Details: true})
+	if err != nil {
+		return nil, err
+	}
+
+	def := &RepoSpec{
+		Id:          r.Id,
+		DisplayName: r.Name,
+		Description: r.Description,
+		Notes:       r.Notes,
+	}
+
+	if len(r.Importers) > 0 {
+		i := r.Importers[0]
+		def.Importer = &ImporterSpec{
+			TypeId: i.ImporterTypeId,
+			Config: withoutHiddenSecrets(i.Config),
+		}
+
+		schedules, _, err := s.ListSyncSchedules(r.Id, i.Id)
+		if err != nil {
+			return nil, err
+		}
+		def.Importer.SyncSchedules = scheduleIntervals(schedules)
+	}
+
+	for _, d := range r.Distributors {
+		ds := &DistributorSpec{
+			Id:          d.Id,
+			TypeId:      d.DistributorTypeId,
+			Config:      withoutHiddenSecrets(d.Config),
+			AutoPublish: d.AutoPublish,
+		}
+
+		schedules, _, err := s.ListPublishSchedules(r.Id, d.Id)
+		if err != nil {
+			return nil, err
+		}
+		ds.PublishSchedules = scheduleIntervals(schedules)
+
+		def.Distributors = append(def.Distributors, ds)
+	}
+
+	return def, nil
+}
+
+// ImportDefinition creates a repository from a definition returned by
+// ExportDefinition, with its importer, distributors and schedules. It fails
+// if the repository exists, use Client.Apply to update it instead.
+func (s *RepositoriesService) ImportDefinition(def *RepoSpec) (*Repository, error) {
+	if def.Id == "" {
+		return nil, fmt.Errorf("pulp: repository definition without id")
+	}
+	if err := checkHiddenSecrets(def); err != nil {
+		return nil, err
+	}
+
+	p := new(Plan)
+	s.client.planCreate(p, def)
+	if err := p.apply(); err != nil {
+		return nil, err
+	}
+
+	r, _, err := s.GetRepository(def.Id, &GetRepositoryOptions{Details: true})
+	return r, err
+}
+
+func withoutHiddenSecrets(config map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(config))
+	for k, v := range config {
+		if v != hiddenSecret {
+			c[k] = v
+		}
+	}
+	return c
+}
+
+// checkHiddenSecrets fails if the definition holds secrets hidden by pulp,
+// which would be imported as the secret itself.
+func checkHiddenSecrets(def *RepoSpec) error {
+	check := func(name string, config map[string]interface{}) error {
+		var keys []string
+		for k, v := range config {
+			if v == hiddenSecret {
+				keys = append(keys, k)
+			}
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+
+		sort.Strings(keys)
+		return fmt.Errorf("pulp: the %s config of repository %s holds secrets hidden by pulp, set the value of %s",
+			name, def.Id, strings.Join(keys, ", "))
+	}
+
+	if def.Importer != nil {
+		if err := check("importer", def.Importer.Config); err != nil {
+			return err
+		}
+	}
+	for _, d := range def.Distributors {
+		if err := check("distributor "+d.Id, d.Config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scheduleIntervals(schedules []*Schedule) []string {
+	var intervals []string
+	for _, s := range schedules {
+		intervals = append(intervals, s.Schedule)
+	}
+	return intervals
+}
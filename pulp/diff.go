@@ -0,0 +1,130 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"sort"
+)
+
+// RepoDiff lists the units found in only one of two repositories. Units of
+// the same package found in both repositories in different versions, like
+// rpms of the same name and arch, are reported as Mismatches instead.
+type RepoDiff struct {
+	OnlyInA    []*Unit
+	OnlyInB    []*Unit
+	Mismatches []*UnitMismatch
+}
+
+// UnitMismatch holds the versions of a package found in only one of the
+// repositories. Key identifies the package, e.g. rpm:zsh.x86_64.
+type UnitMismatch struct {
+	Key string
+	A   []*Unit
+	B   []*Unit
+}
+
+func (d RepoDiff) String() string {
+	return Stringify(d)
+}
+
+// Equal reports if both repositories hold the same units.
+func (d *RepoDiff) Equal() bool {
+	return len(d.OnlyInA) == 0 && len(d.OnlyInB) == 0 && len(d.Mismatches) == 0
+}
+
+// Diff compares the units of the types, all by default, of two
+// repositories. The units are fetched a page at a time.
+func (s *RepositoriesService) Diff(repoA string, repoB string, typeIds ...string) (*RepoDiff, error) {
+	a, err := s.unitsById(repoA, typeIds)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.unitsById(repoB, typeIds)
+	if err != nil {
+		return nil, err
+	}
+
+	onlyA := make(map[string][]*Unit)
+	onlyB := make(map[string][]*Unit)
+	for id, u := range a {
+		if _, ok := b[id]; !ok {
+			onlyA[unitNameKey(u)] = append(onlyA[unitNameKey(u)], u)
+		}
+	}
+	for id, u := range b {
+		if _, ok := a[id]; !ok {
+			onlyB[unitNameKey(u)] = append(onlyB[unitNameKey(u)], u)
+		}
+	}
+
+	d := new(RepoDiff)
+	for key, units := range onlyA {
+		if key != "" && len(onlyB[key]) > 0 {
+			d.Mismatches = append(d.Mismatches, &UnitMismatch{Key: key, A: units, B: onlyB[key]})
+			delete(onlyB, key)
+			continue
+		}
+		d.OnlyInA = append(d.OnlyInA, units...)
+	}
+	for _, units := range onlyB {
+		d.OnlyInB = append(d.OnlyInB, units...)
+	}
+
+	sortUnits(d.OnlyInA)
+	sortUnits(d.OnlyInB)
+	sort.Slice(d.Mismatches, func(i, j int) bool {
+		return d.Mismatches[i].Key < d.Mismatches[j].Key
+	})
+	for _, m := range d.Mismatches {
+		sortUnits(m.A)
+		sortUnits(m.B)
+	}
+	return d, nil
+}
+
+func (s *RepositoriesService) unitsById(repository string, typeIds []string) (map[string]*Unit, error) {
+	units := make(map[string]*Unit)
+
+	it := s.client.Units.NewUnitIterator(repository, NewUnitCriteria(typeIds...), 0)
+	for it.Next() {
+		u := it.Unit()
+		units[u.UnitId] = u
+	}
+	return units, it.Err()
+}
+
+// unitNameKey identifies the package of a unit independently of its
+// version, or is empty for units without versions.
+func unitNameKey(u *Unit) string {
+	switch m := u.Metadata.(type) {
+	case *RpmUnit:
+		return u.UnitTypeId + ":" + m.Name + "." + m.Arch
+	case *PuppetModuleUnit:
+		return u.UnitTypeId + ":" + m.Author + "/" + m.Name
+	case *DockerTagUnit:
+		return u.UnitTypeId + ":" + m.Name
+	case *IsoUnit:
+		return u.UnitTypeId + ":" + m.Name
+	}
+	return ""
+}
+
+func sortUnits(units []*Unit) {
+	sort.Slice(units, func(i, j int) bool {
+		return units[i].UnitId < units[j].UnitId
+	})
+}
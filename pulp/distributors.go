@@ -0,0 +1,239 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	YumDistributorType    = "yum_distributor"
+	DockerDistributorType = "docker_distributor_web"
+	IsoDistributorType    = "iso_distributor"
+	PuppetDistributorType = "puppet_distributor"
+)
+
+type Distributor struct {
+	Id                 string                 `json:"id"`
+	DistributorTypeId  string                 `json:"distributor_type_id"`
+	RepoId             string                 `json:"repo_id"`
+	Config             map[string]interface{} `json:"config"`
+	AutoPublish        bool                   `json:"auto_publish"`
+	LastPublish        PulpTime               `json:"last_publish"`
+	LastUpdated        PulpTime               `json:"last_updated"`
+	LastOverrideConfig map[string]interface{} `json:"last_override_config"`
+	ScheduledPublishes []string               `json:"scheduled_publishes"`
+	Href               string                 `json:"_href"`
+
+	// the fields unknown to the client, encoded again with the distributor
+	ExtraFields map[string]json.RawMessage `json:"-"`
+}
+
+func (d Distributor) String() string {
+	return Stringify(d)
+}
+
+func (d *Distributor) UnmarshalJSON(data []byte) error {
+	type distributor Distributor
+	if err := json.Unmarshal(data, (*distributor)(d)); err != nil {
+		return err
+	}
+
+	var err error
+	d.ExtraFields, err = extraFields(data, d)
+	return err
+}
+
+func (d Distributor) MarshalJSON() ([]byte, error) {
+	type distributor Distributor
+	return marshalWithExtra(distributor(d), d.ExtraFields)
+}
+
+// DecodeConfig decodes the config of the distributor into one of the typed
+// configs, e.g. a *YumDistributorConfig, to update it:
+//
+//	c := new(pulp.YumDistributorConfig)
+//	if err := d.DecodeConfig(c); err != nil {
+//		return err
+//	}
+//	c.RelativeUrl = "prod/" + c.RelativeUrl
+//	client.Repositories.UpdateDistributor(d.RepoId, d.Id,
+//		&pulp.UpdateDistributorOptions{DistributorConfig: c})
+func (d *Distributor) DecodeConfig(v interface{}) error {
+	return decodeConfig(d.Config, v)
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/plugins/pulp_rpm/tech-reference/yum-plugins.html#yum-distributor
+type YumDistributorConfig struct {
+	RelativeUrl    string   `json:"relative_url,omitempty"`
+	Http           bool     `json:"http"`
+	Https          bool     `json:"https"`
+	GpgKey         string   `json:"gpgkey,omitempty"`
+	ChecksumType   string   `json:"checksum_type,omitempty"`
+	AuthCa         string   `json:"auth_ca,omitempty"`
+	AuthCert       string   `json:"auth_cert,omitempty"`
+	GenerateSqlite *bool    `json:"generate_sqlite,omitempty"`
+	Skip           []string `json:"skip,omitempty"`
+
+	// sign repomd.xml on publish with gpg_cmd, which is given the key id in
+	// GPG_KEY_ID and writes repomd.xml.asc
+	GpgSignMetadata *bool  `json:"gpg_sign_metadata,omitempty"`
+	GpgKeyId        string `json:"gpg_key_id,omitempty"`
+	GpgCmd          string `json:"gpg_cmd,omitempty"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/plugins/pulp_docker/tech-reference/distributor.html
+type DockerDistributorConfig struct {
+	DockerPublishDirectory string `json:"docker_publish_directory,omitempty"`
+	Protected              *bool  `json:"protected,omitempty"`
+	RedirectUrl            string `json:"redirect_url,omitempty"`
+	RepoRegistryId         string `json:"repo-registry-id,omitempty"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/plugins/pulp_rpm/tech-reference/iso-plugins.html#iso-distributor
+type IsoDistributorConfig struct {
+	ServeHttp  bool `json:"serve_http"`
+	ServeHttps bool `json:"serve_https"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/plugins/pulp_puppet/tech-reference/plugin_conf.html#distributor
+type PuppetDistributorConfig struct {
+	ServeHttp    bool   `json:"serve_http"`
+	ServeHttps   bool   `json:"serve_https"`
+	HttpDir      string `json:"http_dir,omitempty"`
+	HttpsDir     string `json:"https_dir,omitempty"`
+	AbsolutePath string `json:"absolute_path,omitempty"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/repo/cud.html#associate-a-distributor-with-a-repository
+func (s *RepositoriesService) ListDistributors(repository string) ([]*Distributor, *Response, error) {
+	u := fmt.Sprintf("repositories/%s/distributors/", repository)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var d []*Distributor
+	resp, err := s.client.Do(req, &d)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return d, resp, err
+}
+
+func (s *RepositoriesService) GetDistributor(repository string, distributor string) (*Distributor, *Response, error) {
+	u := fmt.Sprintf("repositories/%s/distributors/%s/", repository, distributor)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d := new(Distributor)
+	resp, err := s.client.Do(req, d)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return d, resp, err
+}
+
+// DistributorConfig takes one of the typed plugin configs, e.g. a
+// *YumDistributorConfig, or a map for plugins not covered by the library.
+type AddDistributorOptions struct {
+	DistributorId     string      `json:"distributor_id,omitempty"`
+	DistributorTypeId string      `json:"distributor_type_id"`
+	DistributorConfig interface{} `json:"distributor_config"`
+	AutoPublish       bool        `json:"auto_publish"`
+}
+
+func (s *RepositoriesService) AddDistributor(repository string, opt *AddDistributorOptions) (*Distributor, *Response, error) {
+	u := fmt.Sprintf("repositories/%s/distributors/", repository)
+
+	req, err := s.client.NewRequest("POST", u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d := new(Distributor)
+	resp, err := s.client.Do(req, d)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return d, resp, err
+}
+
+type UpdateDistributorOptions struct {
+	DistributorConfig interface{} `json:"distributor_config,omitempty"`
+	AutoPublish       *bool       `json:"-"`
+}
+
+type updateDistributorRequest struct {
+	DistributorConfig interface{}            `json:"distributor_config,omitempty"`
+	Delta             map[string]interface{} `json:"delta,omitempty"`
+}
+
+func (s *RepositoriesService) UpdateDistributor(repository string, distributor string, opt *UpdateDistributorOptions) (*CallReport, *Response, error) {
+	if opt == nil {
+		opt = &UpdateDistributorOptions{}
+	}
+	u := fmt.Sprintf("repositories/%s/distributors/%s/", repository, distributor)
+
+	r := &updateDistributorRequest{DistributorConfig: opt.DistributorConfig}
+	if opt.AutoPublish != nil {
+		r.Delta = map[string]interface{}{"auto_publish": *opt.AutoPublish}
+	}
+
+	req, err := s.client.NewRequest("PUT", u, r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cr := new(CallReport)
+	resp, err := s.client.Do(req, cr)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return cr, resp, err
+}
+
+func (s *RepositoriesService) RemoveDistributor(repository string, distributor string) (*CallReport, *Response, error) {
+	u := fmt.Sprintf("repositories/%s/distributors/%s/", repository, distributor)
+
+	req, err := s.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cr := new(CallReport)
+	resp, err := s.client.Do(req, cr)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return cr, resp, err
+}
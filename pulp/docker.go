@@ -0,0 +1,114 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import ()
+
+type DockerService struct {
+	client *Client
+}
+
+func (s *DockerService) ListTags(repository string) ([]*DockerTagUnit, *Response, error) {
+	if err := s.client.requireContentType(DockerTagUnitType, "pulp_docker"); err != nil {
+		return nil, nil, err
+	}
+
+	units, resp, err := s.client.Units.SearchUnits(repository, NewUnitCriteria(DockerTagUnitType))
+	if err != nil {
+		return nil, resp, err
+	}
+
+	var tags []*DockerTagUnit
+	for _, u := range units {
+		if t := u.DockerTag(); t != nil {
+			tags = append(tags, t)
+		}
+	}
+	return tags, resp, err
+}
+
+func (s *DockerService) ListManifests(repository string) ([]*DockerManifestUnit, *Response, error) {
+	if err := s.client.requireContentType(DockerManifestUnitType, "pulp_docker"); err != nil {
+		return nil, nil, err
+	}
+
+	units, resp, err := s.client.Units.SearchUnits(repository, NewUnitCriteria(DockerManifestUnitType))
+	if err != nil {
+		return nil, resp, err
+	}
+
+	var manifests []*DockerManifestUnit
+	for _, u := range units {
+		if m := u.DockerManifest(); m != nil {
+			manifests = append(manifests, m)
+		}
+	}
+	return manifests, resp, err
+}
+
+func (s *DockerService) ListBlobs(repository string) ([]*DockerBlobUnit, *Response, error) {
+	if err := s.client.requireContentType(DockerBlobUnitType, "pulp_docker"); err != nil {
+		return nil, nil, err
+	}
+
+	units, resp, err := s.client.Units.SearchUnits(repository, NewUnitCriteria(DockerBlobUnitType))
+	if err != nil {
+		return nil, resp, err
+	}
+
+	var blobs []*DockerBlobUnit
+	for _, u := range units {
+		if b := u.DockerBlob(); b != nil {
+			blobs = append(blobs, b)
+		}
+	}
+	return blobs, resp, err
+}
+
+// TagManifest points the tag to the manifest with the given digest,
+// creating the tag or moving an existing one.
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/plugins/pulp_docker/user-guide/recipes.html#tagging-a-manifest
+func (s *DockerService) TagManifest(repository string, tag string, digest string) (*CallReport, *Response, error) {
+	if err := s.client.requireContentType(DockerTagUnitType, "pulp_docker"); err != nil {
+		return nil, nil, err
+	}
+
+	return s.client.Units.ImportUpload(repository, &ImportUploadOptions{
+		UnitTypeId: DockerTagUnitType,
+		UnitKey: map[string]string{
+			"name":    tag,
+			"repo_id": repository,
+		},
+		UnitMetadata: map[string]string{
+			"name":   tag,
+			"digest": digest,
+		},
+	})
+}
+
+// RemoveTags removes the tags from the repository, the manifests stay in
+// the repository.
+func (s *DockerService) RemoveTags(repository string, tags ...string) (*CallReport, *Response, error) {
+	if err := s.client.requireContentType(DockerTagUnitType, "pulp_docker"); err != nil {
+		return nil, nil, err
+	}
+
+	c := NewUnitCriteria(DockerTagUnitType).WhereUnit(In("name", Strings(tags)...))
+	return s.client.Units.UnassociateUnits(repository, c)
+}
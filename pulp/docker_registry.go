@@ -0,0 +1,185 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DockerRegistry tells where the docker distributor of a repository
+// publishes it. Crane serves the registry api at /v2/<RepoRegistryId>/ and
+// redirects the clients to the content published at RedirectUrl.
+type DockerRegistry struct {
+	RepoId         string
+	RepoRegistryId string
+	RedirectUrl    string
+}
+
+func (r DockerRegistry) String() string {
+	return Stringify(r)
+}
+
+// PullSpec returns the name to pull a tag of the repository through the
+// crane registry at craneHost, e.g. "registry.example.com:5000/busybox:latest".
+func (r *DockerRegistry) PullSpec(craneHost string, tag string) string {
+	return strings.TrimRight(craneHost, "/") + "/" + r.RepoRegistryId + ":" + tag
+}
+
+// Registry returns the registry settings of the docker distributor of the
+// repository. The redirect url defaults to the path where pulp publishes
+// the repository.
+func (s *DockerService) Registry(repository string) (*DockerRegistry, error) {
+	if err := s.client.requireContentType(DockerManifestUnitType, "pulp_docker"); err != nil {
+		return nil, err
+	}
+
+	distributors, _, err := s.client.Repositories.ListDistributors(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range distributors {
+		if d.DistributorTypeId != DockerDistributorType {
+			continue
+		}
+
+		r := &DockerRegistry{
+			RepoId:         repository,
+			RepoRegistryId: configString(d.Config, "repo-registry-id", repository),
+			RedirectUrl:    configString(d.Config, "redirect_url", ""),
+		}
+		if r.RedirectUrl == "" {
+			r.RedirectUrl = "/pulp/docker/v2/" + r.RepoRegistryId + "/"
+		}
+		if !strings.HasSuffix(r.RedirectUrl, "/") {
+			r.RedirectUrl += "/"
+		}
+		return r, nil
+	}
+	return nil, fmt.Errorf("pulp: repository %s has no docker distributor", repository)
+}
+
+// PublishedTags returns the tags listed in the published tags/list of the
+// repository, as served by crane.
+func (s *DockerService) PublishedTags(repository string) ([]string, error) {
+	r, err := s.Registry(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.client.fetchContent(r.RedirectUrl + "tags/list")
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("pulp: invalid tags/list of %s: %v", repository, err)
+	}
+	sort.Strings(list.Tags)
+	return list.Tags, nil
+}
+
+// PublishedManifest returns the published manifest of the repository for a
+// tag or a digest.
+func (s *DockerService) PublishedManifest(repository string, reference string) ([]byte, error) {
+	r, err := s.Registry(repository)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.fetchContent(r.RedirectUrl + "manifests/" + reference)
+}
+
+// DockerPublishCheck compares the tags of a docker repository with the
+// published ones. MissingManifests lists the digests of the tagged
+// manifests which are not published.
+type DockerPublishCheck struct {
+	Registry         *DockerRegistry
+	Tags             []string
+	PublishedTags    []string
+	MissingTags      []string
+	UnexpectedTags   []string
+	MissingManifests []string
+}
+
+func (c DockerPublishCheck) String() string {
+	return Stringify(c)
+}
+
+// Consistent reports whether the repository is published as is.
+func (c *DockerPublishCheck) Consistent() bool {
+	return len(c.MissingTags) == 0 && len(c.UnexpectedTags) == 0 && len(c.MissingManifests) == 0
+}
+
+// CheckPublished verifies that the tags of the repository and their
+// manifests are published.
+func (s *DockerService) CheckPublished(repository string) (*DockerPublishCheck, error) {
+	r, err := s.Registry(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, _, err := s.ListTags(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	published, err := s.PublishedTags(repository)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	check := &DockerPublishCheck{Registry: r, PublishedTags: published}
+
+	isPublished := make(map[string]bool)
+	for _, t := range published {
+		isPublished[t] = true
+	}
+
+	expected := make(map[string]bool)
+	for _, t := range tags {
+		expected[t.Name] = true
+		check.Tags = append(check.Tags, t.Name)
+		if !isPublished[t.Name] {
+			check.MissingTags = append(check.MissingTags, t.Name)
+			continue
+		}
+
+		_, err := s.client.fetchContent(r.RedirectUrl + "manifests/" + t.ManifestDigest)
+		if errors.Is(err, ErrNotFound) {
+			check.MissingManifests = append(check.MissingManifests, t.ManifestDigest)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	for _, t := range published {
+		if !expected[t] {
+			check.UnexpectedTags = append(check.UnexpectedTags, t)
+		}
+	}
+
+	sort.Strings(check.Tags)
+	sort.Strings(check.MissingTags)
+	return check, nil
+}
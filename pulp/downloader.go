@@ -0,0 +1,232 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContentDownloader fetches the content published by the distributors of
+// a repository, like the rpms and repodata of a yum repository:
+//
+//	d := client.NewContentDownloader(4)
+//	files, err := d.Download("my-repo", []string{"repodata/repomd.xml"}, "/srv/mirror")
+type ContentDownloader struct {
+	client *Client
+
+	// number of files downloaded at a time
+	Concurrency int
+
+	// resumes partial downloads of files already present in the
+	// destination. The file is only completed if it did not change on the
+	// server since it was last modified locally, see DownloadFile.
+	Resume bool
+}
+
+// NewContentDownloader returns a downloader fetching concurrency files at
+// a time.
+func (c *Client) NewContentDownloader(concurrency int) *ContentDownloader {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &ContentDownloader{client: c, Concurrency: concurrency}
+}
+
+// RepoPath returns the path where the repository is published.
+func (d *ContentDownloader) RepoPath(repository string) (string, error) {
+	return d.client.PublishedRepoPath(repository)
+}
+
+// PublishedRepoPath returns the path where the repository is published,
+// from the relative url of its yum, iso or deb distributor, the registry id
+// of its docker distributor or the relative path of its ostree distributor.
+// Python repositories are published under their id.
+func (c *Client) PublishedRepoPath(repository string) (string, error) {
+	distributors, _, err := c.Repositories.ListDistributors(repository)
+	if err != nil {
+		return "", err
+	}
+
+	for _, dist := range distributors {
+		switch dist.DistributorTypeId {
+		case YumDistributorType:
+			return "/pulp/repos/" + strings.Trim(configString(dist.Config, "relative_url", repository), "/") + "/", nil
+		case IsoDistributorType:
+			return IsoContentPath(configString(dist.Config, "relative_url", repository)), nil
+		case DockerDistributorType:
+			return "/pulp/docker/v2/" + configString(dist.Config, "repo-registry-id", repository) + "/", nil
+		case OstreeDistributorType:
+			return OstreeContentPath(configString(dist.Config, "relative_path", repository)), nil
+		case PythonDistributorType:
+			return PythonContentPath(repository), nil
+		case DebDistributorType:
+			return DebContentPath(configString(dist.Config, "relative_url", repository)), nil
+		}
+	}
+	return "", fmt.Errorf("pulp: repository %s has no published distributor", repository)
+}
+
+func configString(config map[string]interface{}, key string, def string) string {
+	if v, ok := config[key].(string); ok && v != "" {
+		return v
+	}
+	return def
+}
+
+// Download fetches the files, given by their path in the published
+// repository, into destDir keeping their relative path. It returns the
+// downloaded files in the order of the paths; the error is a *BulkError
+// listing the failed downloads by path.
+func (d *ContentDownloader) Download(repository string, paths []string, destDir string) ([]string, error) {
+	base, err := d.RepoPath(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	concurrency := d.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	files := make([]string, len(paths))
+	errs := make(map[string]error)
+	var mu sync.Mutex
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, p := range paths {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, p string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			file := filepath.Join(destDir, filepath.FromSlash(path.Clean("/"+p)))
+			if err := d.DownloadFile(base+strings.TrimLeft(p, "/"), file); err != nil {
+				mu.Lock()
+				errs[p] = err
+				mu.Unlock()
+				return
+			}
+			files[i] = file
+		}(i, p)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return files, &BulkError{Errors: errs}
+	}
+	return files, nil
+}
+
+// DownloadFile fetches a published file into file, creating its directory.
+// With Resume a partially downloaded file is completed with a range
+// request, sent with an If-Range on the modification time of the file,
+// which is set to the Last-Modified of the downloaded files. The file is
+// downloaded again if it changed on the server since, or if the server
+// does not support ranges.
+func (d *ContentDownloader) DownloadFile(fileUrl string, file string) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+
+	var offset int64
+	var modTime time.Time
+	if d.Resume {
+		if fi, err := os.Stat(file); err == nil && fi.Size() > 0 {
+			offset, modTime = fi.Size(), fi.ModTime()
+		}
+	}
+
+	err := d.downloadFile(fileUrl, file, offset, modTime)
+	if err == errRangeNotSatisfiable {
+		// the file is longer than the one on the server
+		err = d.downloadFile(fileUrl, file, 0, time.Time{})
+	}
+	return err
+}
+
+var errRangeNotSatisfiable = errors.New("pulp: range not satisfiable")
+
+func (d *ContentDownloader) downloadFile(fileUrl string, file string, offset int64, modTime time.Time) error {
+	req, err := d.client.NewContentRequest("GET", fileUrl)
+	if err != nil {
+		return err
+	}
+	req = withoutCache(d.client.withContext(req))
+
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", modTime.UTC().Format(http.TimeFormat))
+	}
+
+	hc := *d.client.httpClient()
+	hc.Timeout = 0
+
+	resp, err := d.client.do(&hc, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		flags = os.O_WRONLY | os.O_APPEND
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// the file is complete if the server has the same length
+		if resp.Header.Get("Content-Range") == fmt.Sprintf("bytes */%d", offset) {
+			return nil
+		}
+		return errRangeNotSatisfiable
+	default:
+		if err := CheckResponse(resp); err != nil {
+			d.client.onError(req, err)
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(file, flags, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, resp.Body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return timeoutError(err)
+	}
+
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		return os.Chtimes(file, t, t)
+	}
+	return nil
+}
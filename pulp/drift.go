@@ -0,0 +1,133 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// ConfigFingerprint returns a hash of the config, e.g. a *YumImporterConfig
+// or the Config of an Importer, which does not depend on the order of the
+// keys nor on the go type holding the config. Configs with the same json
+// values have the same fingerprint.
+func ConfigFingerprint(config interface{}) (string, error) {
+	v, err := jsonValue(config)
+	if err != nil {
+		return "", err
+	}
+
+	// maps are encoded with sorted keys
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// jsonValue returns v as decoded from its json encoding.
+func jsonValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var n interface{}
+	if err := json.Unmarshal(data, &n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// hiddenSecret is the value pulp returns in place of the secrets of the
+// importer configs, like proxy_password or basic_auth_password.
+const hiddenSecret = "*****"
+
+// configDiff returns the sorted keys of want which have another value in
+// have. The secrets hidden by pulp cannot be compared and are left out.
+func configDiff(want map[string]interface{}, have map[string]interface{}) []string {
+	var keys []string
+	for k, v := range want {
+		if have[k] == hiddenSecret {
+			continue
+		}
+
+		n, err := jsonValue(v)
+		if err != nil || !reflect.DeepEqual(n, have[k]) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ConfigDrift lists the config keys of an importer or distributor whose
+// value on the server differs from the desired one. Missing is set when
+// the importer or distributor does not exist, or has another type.
+type ConfigDrift struct {
+	Resource string // importer or distributor
+	Id       string
+	Missing  bool
+	Keys     []string
+}
+
+func (d ConfigDrift) String() string {
+	return Stringify(d)
+}
+
+// DetectDrift compares the importer and distributor configs of the spec
+// with the ones of the repository on the server. Only the keys of the spec
+// are compared.
+func (s *RepositoriesService) DetectDrift(repository string, desired *RepoSpec) ([]*ConfigDrift, error) {
+	r, _, err := s.GetRepository(repository, &GetRepositoryOptions{Details: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []*ConfigDrift
+
+	if want := desired.Importer; want != nil {
+		live := r.Importer()
+		switch {
+		case live == nil || live.ImporterTypeId != want.TypeId:
+			drifts = append(drifts, &ConfigDrift{Resource: "importer", Id: want.TypeId, Missing: true})
+		default:
+			if keys := configDiff(want.Config, live.Config); len(keys) > 0 {
+				drifts = append(drifts, &ConfigDrift{Resource: "importer", Id: live.Id, Keys: keys})
+			}
+		}
+	}
+
+	for _, want := range desired.Distributors {
+		live := r.Distributor(want.Id)
+		switch {
+		case live == nil || live.DistributorTypeId != want.TypeId:
+			drifts = append(drifts, &ConfigDrift{Resource: "distributor", Id: want.Id, Missing: true})
+		default:
+			if keys := configDiff(want.Config, live.Config); len(keys) > 0 {
+				drifts = append(drifts, &ConfigDrift{Resource: "distributor", Id: want.Id, Keys: keys})
+			}
+		}
+	}
+
+	return drifts, nil
+}
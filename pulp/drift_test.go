@@ -0,0 +1,39 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConfigDiff(t *testing.T) {
+	want := map[string]interface{}{
+		"feed":           "http://example.com/zoo/",
+		"max_downloads":  4,
+		"proxy_password": "secret",
+	}
+	have := map[string]interface{}{
+		"feed":           "http://example.com/old/",
+		"max_downloads":  float64(4),
+		"proxy_password": hiddenSecret,
+	}
+
+	if keys := configDiff(want, have); !reflect.DeepEqual(keys, []string{"feed"}) {
+		t.Errorf("configDiff() = %v, want [feed]", keys)
+	}
+}
@@ -0,0 +1,128 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// RedHatCDN is the base url of the Red Hat content delivery network.
+const RedHatCDN = "https://cdn.redhat.com"
+
+// RedHatUEPCACert is where subscription-manager stores the CA certificate
+// of the Red Hat CDN.
+const RedHatUEPCACert = "/etc/rhsm/ca/redhat-uep.pem"
+
+// Entitlement gives access to the content of a subscription, e.g. exported
+// from a Candlepin or Katello manifest. The content is downloaded from the
+// CDN with the entitlement certificate and key as feed certificates.
+type Entitlement struct {
+	FeedCertificates
+
+	// base url of the content, RedHatCDN by default
+	CDN string
+}
+
+func (e Entitlement) String() string {
+	return Stringify(e)
+}
+
+// LoadEntitlement reads an entitlement certificate and key pair, each given
+// as a file path or as PEM, and the CA certificate of the CDN, e.g.
+// RedHatUEPCACert. The key may be left empty when it is in the certificate
+// file.
+func LoadEntitlement(cert string, key string, caCert string) (*Entitlement, error) {
+	if cert == "" {
+		return nil, errors.New("pulp: an entitlement requires a certificate")
+	}
+
+	fc, err := LoadFeedCertificates(caCert, cert, key)
+	if err != nil {
+		return nil, err
+	}
+	return &Entitlement{FeedCertificates: *fc, CDN: RedHatCDN}, nil
+}
+
+// Feed returns the url of the content path, e.g.
+// /content/dist/rhel/server/7/$releasever/$basearch/os, with the variables
+// replaced by vars, e.g. {"releasever": "7Server", "basearch": "x86_64"}.
+func (e *Entitlement) Feed(contentPath string, vars map[string]string) string {
+	contentPath = expandContentPath(contentPath, vars)
+
+	cdn := e.CDN
+	if cdn == "" {
+		cdn = RedHatCDN
+	}
+	return strings.TrimSuffix(cdn, "/") + "/" + strings.TrimPrefix(contentPath, "/")
+}
+
+// YumImporterConfig returns the config of an importer syncing the content
+// path of the entitlement.
+func (e *Entitlement) YumImporterConfig(contentPath string, vars map[string]string) (*YumImporterConfig, error) {
+	c := &YumImporterConfig{Feed: e.Feed(contentPath, vars)}
+	if err := c.SetFeedCertificates(&e.FeedCertificates); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// CDNRelativeUrl is the relative url a CDN content path is published at,
+// the content path without its /content/ prefix and variables replaced.
+func CDNRelativeUrl(contentPath string, vars map[string]string) string {
+	contentPath = expandContentPath(contentPath, vars)
+	return strings.TrimPrefix(strings.Trim(contentPath, "/"), "content/")
+}
+
+// expandContentPath replaces the $var and ${var} variables of the content
+// path, leaving the unknown ones.
+func expandContentPath(contentPath string, vars map[string]string) string {
+	return os.Expand(contentPath, func(name string) string {
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return "$" + name
+	})
+}
+
+// CreateCDNRepository creates an rpm repository syncing the content path
+// of the entitlement, published over https at the CDNRelativeUrl of the
+// content path.
+func (s *RepositoriesService) CreateCDNRepository(repository string, e *Entitlement, contentPath string, vars map[string]string) (*Repository, error) {
+	importerConfig, err := e.YumImporterConfig(contentPath, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	r, _, err := s.CreateRepository(&CreateRepositoryOptions{
+		Id:             repository,
+		Notes:          map[string]string{RepoTypeNote: RpmRepoType},
+		ImporterTypeId: YumImporterType,
+		ImporterConfig: importerConfig,
+		Distributors: []*AddDistributorOptions{{
+			DistributorId:     YumDistributorType,
+			DistributorTypeId: YumDistributorType,
+			DistributorConfig: &YumDistributorConfig{
+				RelativeUrl: CDNRelativeUrl(contentPath, vars),
+				Https:       true,
+			},
+			AutoPublish: true,
+		}},
+	})
+	return r, err
+}
@@ -0,0 +1,42 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"testing"
+)
+
+func TestExpandContentPath(t *testing.T) {
+	vars := map[string]string{
+		"release":    "7",
+		"releasever": "7Server",
+		"basearch":   "x86_64",
+	}
+
+	tests := map[string]string{
+		"/content/dist/rhel/server/7/$releasever/$basearch/os":     "/content/dist/rhel/server/7/7Server/x86_64/os",
+		"/content/dist/rhel/server/7/${releasever}/${basearch}/os": "/content/dist/rhel/server/7/7Server/x86_64/os",
+		"/content/dist/rhel/server/$release/$arch/os":              "/content/dist/rhel/server/7/$arch/os",
+		"/content/$/os": "/content/$/os",
+	}
+
+	for path, want := range tests {
+		if got := expandContentPath(path, vars); got != want {
+			t.Errorf("expandContentPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"fmt"
+)
+
+// ListErrata lists the errata of a yum repository.
+func (s *UnitsService) ListErrata(repository string) ([]*ErratumUnit, *Response, error) {
+	if err := s.client.requireContentType(ErratumUnitType, "pulp_rpm"); err != nil {
+		return nil, nil, err
+	}
+
+	units, resp, err := s.SearchUnits(repository, NewUnitCriteria(ErratumUnitType))
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return errata(units), resp, err
+}
+
+// GetErratum returns an erratum of the repository, e.g. RHSA-2016:0176,
+// including its package list. The error matches ErrNotFound when the
+// repository has no such erratum.
+func (s *UnitsService) GetErratum(repository string, erratum string) (*ErratumUnit, *Response, error) {
+	if err := s.client.requireContentType(ErratumUnitType, "pulp_rpm"); err != nil {
+		return nil, nil, err
+	}
+
+	c := NewUnitCriteria(ErratumUnitType).WhereUnit(Eq("id", erratum))
+
+	units, resp, err := s.SearchUnits(repository, c)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	e := errata(units)
+	if len(e) == 0 {
+		return nil, resp, fmt.Errorf("%w: erratum %s in repository %s", ErrNotFound, erratum, repository)
+	}
+
+	return e[0], resp, err
+}
+
+// CopyErrata copies the errata with the given ids between the repositories,
+// along with the packages they reference.
+func (s *UnitsService) CopyErrata(source string, destination string, errata []string) (*CallReport, *Response, error) {
+	if err := s.client.requireContentType(ErratumUnitType, "pulp_rpm"); err != nil {
+		return nil, nil, err
+	}
+
+	c := NewUnitCriteria(ErratumUnitType).WhereUnit(In("id", Strings(errata)...))
+	return s.CopyUnits(source, destination, c, map[string]interface{}{"recursive": true})
+}
+
+func errata(units []*Unit) []*ErratumUnit {
+	var e []*ErratumUnit
+	for _, u := range units {
+		if m := u.Erratum(); m != nil {
+			e = append(e, m)
+		}
+	}
+	return e
+}
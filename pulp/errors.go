@@ -0,0 +1,66 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// Errors returned by the client can be tested against these with errors.Is,
+// e.g. errors.Is(err, pulp.ErrNotFound).
+var (
+	ErrNotFound     = errors.New("pulp: not found")
+	ErrUnauthorized = errors.New("pulp: unauthorized")
+	ErrConflict     = errors.New("pulp: conflict")
+	ErrTaskFailed   = errors.New("pulp: task failed")
+	ErrTimeout      = errors.New("pulp: timeout")
+	ErrUnsupported  = errors.New("pulp: unsupported by the server")
+
+	ErrChecksumMismatch = errors.New("pulp: checksum mismatch")
+	ErrBadSignature     = errors.New("pulp: bad signature")
+)
+
+// timeoutError makes the errors of requests reaching their deadline match
+// ErrTimeout.
+func timeoutError(err error) error {
+	if err == nil || errors.Is(err, ErrTimeout) {
+		return err
+	}
+
+	var ne net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &ne) && ne.Timeout() {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return err
+}
+
+// Is maps the status of the response to the sentinel errors. Both 401 and
+// 403 responses are ErrUnauthorized.
+func (r *ErrorResponse) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return r.IsNotFound()
+	case ErrUnauthorized:
+		return r.IsUnauthorized() || r.IsForbidden()
+	case ErrConflict:
+		return r.IsConflict()
+	}
+	return false
+}
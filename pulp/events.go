@@ -0,0 +1,154 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"fmt"
+)
+
+type EventsService struct {
+	client *Client
+}
+
+// event types
+const (
+	EventAll               = "*"
+	EventRepoSyncStart     = "repo.sync.start"
+	EventRepoSyncFinish    = "repo.sync.finish"
+	EventRepoPublishStart  = "repo.publish.start"
+	EventRepoPublishFinish = "repo.publish.finish"
+)
+
+// notifier types
+const (
+	HttpNotifier  = "http"
+	AmqpNotifier  = "amqp"
+	EmailNotifier = "email"
+)
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/events/index.html
+type EventListener struct {
+	Id             string                 `json:"id"`
+	NotifierTypeId string                 `json:"notifier_type_id"`
+	NotifierConfig map[string]interface{} `json:"notifier_config"`
+	EventTypes     []string               `json:"event_types"`
+	Href           string                 `json:"_href"`
+}
+
+func (e EventListener) String() string {
+	return Stringify(e)
+}
+
+// the http notifier posts the event to Url
+type HttpNotifierConfig struct {
+	Url      string `json:"url"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// the amqp notifier publishes the event to the exchange of the pulp broker
+type AmqpNotifierConfig struct {
+	Exchange string `json:"exchange,omitempty"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/event/retrieval.html
+func (s *EventsService) ListEventListeners() ([]*EventListener, *Response, error) {
+	req, err := s.client.NewRequest("GET", "events/", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var e []*EventListener
+	resp, err := s.client.Do(req, &e)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return e, resp, err
+}
+
+func (s *EventsService) GetEventListener(listener string) (*EventListener, *Response, error) {
+	u := fmt.Sprintf("events/%s/", listener)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e := new(EventListener)
+	resp, err := s.client.Do(req, e)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return e, resp, err
+}
+
+// NotifierConfig takes a *HttpNotifierConfig or *AmqpNotifierConfig.
+type EventListenerOptions struct {
+	NotifierTypeId string      `json:"notifier_type_id,omitempty"`
+	NotifierConfig interface{} `json:"notifier_config,omitempty"`
+	EventTypes     []string    `json:"event_types,omitempty"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/event/crud.html
+func (s *EventsService) CreateEventListener(opt *EventListenerOptions) (*EventListener, *Response, error) {
+	req, err := s.client.NewRequest("POST", "events/", opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e := new(EventListener)
+	resp, err := s.client.Do(req, e)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return e, resp, err
+}
+
+// the notifier type of a listener can not be changed
+func (s *EventsService) UpdateEventListener(listener string, opt *EventListenerOptions) (*EventListener, *Response, error) {
+	u := fmt.Sprintf("events/%s/", listener)
+
+	req, err := s.client.NewRequest("PUT", u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e := new(EventListener)
+	resp, err := s.client.Do(req, e)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return e, resp, err
+}
+
+func (s *EventsService) DeleteEventListener(listener string) (*Response, error) {
+	u := fmt.Sprintf("events/%s/", listener)
+
+	req, err := s.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
@@ -0,0 +1,196 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	ExportDistributorType      = "export_distributor"
+	GroupExportDistributorType = "group_export_distributor"
+)
+
+// ExportDistributorConfig configures the export distributors of repositories
+// and repository groups. It is also used to override the configuration of
+// a single export.
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/plugins/pulp_rpm/tech-reference/export-distributor.html
+type ExportDistributorConfig struct {
+	Http        *bool    `json:"http,omitempty"`
+	Https       *bool    `json:"https,omitempty"`
+	RelativeUrl string   `json:"relative_url,omitempty"`
+	IsoPrefix   string   `json:"iso_prefix,omitempty"`
+	IsoSize     int      `json:"iso_size,omitempty"`
+	Skip        []string `json:"skip,omitempty"`
+	ExportDir   string   `json:"export_dir,omitempty"`
+
+	// only export the content associated between the dates, an
+	// incremental export; set with ExportDate
+	StartDate string `json:"start_date,omitempty"`
+	EndDate   string `json:"end_date,omitempty"`
+}
+
+// ExportDate formats t for the StartDate and EndDate of an export.
+func ExportDate(t time.Time) string {
+	return criteriaTime(t)
+}
+
+// ExportRepository publishes the repository with its export distributor.
+// The ISOs are written to ExportDir if set, otherwise they are served over
+// http(s) at RepoExportPath(relative url).
+func (s *RepositoriesService) ExportRepository(repository string, distributor string, override *ExportDistributorConfig) (*CallReport, *Response, error) {
+	opt := &PublishOptions{Id: distributor}
+	if override != nil {
+		opt.OverrideConfig = override
+	}
+	return s.PublishRepository(repository, opt)
+}
+
+// ExportRepoGroup publishes the group with its export distributor. The ISOs
+// are served at GroupExportPath(group) unless ExportDir is set.
+func (s *RepoGroupsService) ExportRepoGroup(group string, distributor string, override *ExportDistributorConfig) (*CallReport, *Response, error) {
+	opt := &PublishOptions{Id: distributor}
+	if override != nil {
+		opt.OverrideConfig = override
+	}
+	return s.PublishRepoGroup(group, opt)
+}
+
+// RepoExportPath is where the exports of a repository are served, the
+// relative url defaults to the repository id.
+func RepoExportPath(relativeUrl string) string {
+	return "/pulp/exports/repos/" + strings.Trim(relativeUrl, "/") + "/"
+}
+
+func GroupExportPath(group string) string {
+	return "/pulp/exports/repo_group/" + group + "/"
+}
+
+var isoLinkRegexp = regexp.MustCompile(`href="([^"?/]+\.iso)"`)
+
+// ListExportedISOs returns the urls of the ISOs served at an export path.
+func (c *Client) ListExportedISOs(exportPath string) ([]string, error) {
+	req, err := c.NewContentRequest("GET", exportPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var index bytes.Buffer
+	if _, err := c.Download(req, &index); err != nil {
+		return nil, err
+	}
+
+	var isos []string
+	for _, m := range isoLinkRegexp.FindAllStringSubmatch(index.String(), -1) {
+		u, err := req.URL.Parse(m[1])
+		if err != nil {
+			return nil, err
+		}
+		isos = append(isos, u.String())
+	}
+	return isos, nil
+}
+
+// DownloadExportedISO writes an exported ISO to w.
+func (c *Client) DownloadExportedISO(isoUrl string, w io.Writer) (*Response, error) {
+	req, err := c.NewContentRequest("GET", isoUrl)
+	if err != nil {
+		return nil, err
+	}
+	return c.Download(req, w)
+}
+
+// ExportRepositoryISOs exports the repository, waits for the export to
+// finish and downloads the ISOs served over http(s) to downloadDir. It
+// returns the downloaded files, or the ISO urls if downloadDir is empty.
+func (c *Client) ExportRepositoryISOs(repository string, distributor string, override *ExportDistributorConfig, downloadDir string, poll *PollOptions) ([]string, error) {
+	cr, _, err := c.Repositories.ExportRepository(repository, distributor, override)
+	if err != nil {
+		return nil, err
+	}
+
+	relativeUrl := repository
+	if override != nil && override.RelativeUrl != "" {
+		relativeUrl = override.RelativeUrl
+	} else if d, _, err := c.Repositories.GetDistributor(repository, distributor); err == nil {
+		if r, ok := d.Config["relative_url"].(string); ok && r != "" {
+			relativeUrl = r
+		}
+	}
+
+	return c.exportISOs(cr, RepoExportPath(relativeUrl), downloadDir, poll)
+}
+
+// IncrementalExport exports the content associated with the repository
+// since the date, waits for the export and returns the urls of the ISOs
+// holding the exported content, for a later import on a disconnected pulp.
+func (c *Client) IncrementalExport(repository string, distributor string, since time.Time, poll *PollOptions) ([]string, error) {
+	override := &ExportDistributorConfig{StartDate: ExportDate(since)}
+	return c.ExportRepositoryISOs(repository, distributor, override, "", poll)
+}
+
+// ExportRepoGroupISOs is ExportRepositoryISOs for repository groups.
+func (c *Client) ExportRepoGroupISOs(group string, distributor string, override *ExportDistributorConfig, downloadDir string, poll *PollOptions) ([]string, error) {
+	cr, _, err := c.RepoGroups.ExportRepoGroup(group, distributor, override)
+	if err != nil {
+		return nil, err
+	}
+	return c.exportISOs(cr, GroupExportPath(group), downloadDir, poll)
+}
+
+func (c *Client) exportISOs(cr *CallReport, exportPath string, downloadDir string, poll *PollOptions) ([]string, error) {
+	if _, err := cr.WaitAll(c, poll); err != nil {
+		return nil, err
+	}
+
+	isos, err := c.ListExportedISOs(exportPath)
+	if err != nil || downloadDir == "" {
+		return isos, err
+	}
+
+	var files []string
+	for _, iso := range isos {
+		file := filepath.Join(downloadDir, path.Base(iso))
+		if err := c.downloadToFile(iso, file); err != nil {
+			return files, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func (c *Client) downloadToFile(fileUrl string, file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.DownloadExportedISO(fileUrl, f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,136 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// FeedCertificates are the PEM encoded certificates an importer uses to
+// download from a protected feed, like an entitlement certificate and key
+// for the Red Hat CDN.
+type FeedCertificates struct {
+	CaCert     string
+	ClientCert string
+	ClientKey  string
+}
+
+func (fc FeedCertificates) String() string {
+	return Stringify(fc)
+}
+
+// LoadFeedCertificates reads the certificates, each given as a file path or
+// as PEM. Empty ones are left empty. The key may be left empty when it is
+// in the client certificate file.
+func LoadFeedCertificates(caCert string, clientCert string, clientKey string) (*FeedCertificates, error) {
+	var fc FeedCertificates
+	var err error
+
+	if fc.CaCert, err = ReadPEM(caCert); err != nil {
+		return nil, err
+	}
+	if fc.ClientCert, err = ReadPEM(clientCert); err != nil {
+		return nil, err
+	}
+	if fc.ClientKey, err = ReadPEM(clientKey); err != nil {
+		return nil, err
+	}
+	return &fc, nil
+}
+
+// ReadPEM returns s if it is PEM encoded, else the content of the file s.
+// It fails if no PEM block is found.
+func ReadPEM(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+
+	data := []byte(s)
+	if !strings.HasPrefix(strings.TrimSpace(s), "-----BEGIN") {
+		var err error
+		if data, err = ioutil.ReadFile(s); err != nil {
+			return "", err
+		}
+	}
+
+	if block, _ := pem.Decode(data); block == nil {
+		return "", fmt.Errorf("pulp: no PEM data found in %.40q", s)
+	}
+	return string(data), nil
+}
+
+func (fc *FeedCertificates) validate() error {
+	if fc.ClientKey != "" && fc.ClientCert == "" {
+		return errors.New("pulp: a client key requires a client certificate")
+	}
+	return nil
+}
+
+// SetFeedCertificates sets the feed certificates of the config.
+func (c *YumImporterConfig) SetFeedCertificates(fc *FeedCertificates) error {
+	if err := fc.validate(); err != nil {
+		return err
+	}
+	c.SslCaCert, c.SslClientCert, c.SslClientKey = fc.CaCert, fc.ClientCert, fc.ClientKey
+	return nil
+}
+
+// SetFeedCertificates sets the feed certificates of the config.
+func (c *IsoImporterConfig) SetFeedCertificates(fc *FeedCertificates) error {
+	if err := fc.validate(); err != nil {
+		return err
+	}
+	c.SslCaCert, c.SslClientCert, c.SslClientKey = fc.CaCert, fc.ClientCert, fc.ClientKey
+	return nil
+}
+
+// FeedCertificates returns the feed certificates configured on the
+// importer.
+func (c *ImporterConfig) FeedCertificates() *FeedCertificates {
+	return &FeedCertificates{
+		CaCert:     c.SslCaCert,
+		ClientCert: c.SslClientCert,
+		ClientKey:  c.SslClientKey,
+	}
+}
+
+// UpdateFeedCertificates replaces the feed certificates of the importer of
+// the repository. Empty certificates are removed from the config.
+func (s *RepositoriesService) UpdateFeedCertificates(repository string, importer string, fc *FeedCertificates) (*CallReport, *Response, error) {
+	if err := fc.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	config := map[string]interface{}{
+		"ssl_ca_cert":     nullIfEmpty(fc.CaCert),
+		"ssl_client_cert": nullIfEmpty(fc.ClientCert),
+		"ssl_client_key":  nullIfEmpty(fc.ClientKey),
+	}
+	return s.UpdateImporter(repository, importer, config)
+}
+
+// a null value removes a key from a plugin config
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
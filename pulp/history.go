@@ -0,0 +1,176 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// results of a sync or publish
+const (
+	ResultSuccess = "success"
+	ResultFailed  = "failed"
+	ResultError   = "error"
+	ResultSkipped = "skipped"
+)
+
+type HistoryOptions struct {
+	// number of entries to return, all by default
+	Limit int `url:"limit,omitempty" json:"limit,omitempty"`
+
+	// SortAscending or SortDescending on the start time, newest first by
+	// default
+	Sort string `url:"sort,omitempty" json:"sort,omitempty"`
+
+	StartDate *time.Time `url:"start_date,omitempty" json:"start_date,omitempty"`
+	EndDate   *time.Time `url:"end_date,omitempty" json:"end_date,omitempty"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/repo/sync.html#retrieving-sync-history
+type SyncResult struct {
+	Id             string                 `json:"id"`
+	RepoId         string                 `json:"repo_id"`
+	ImporterId     string                 `json:"importer_id"`
+	ImporterTypeId string                 `json:"importer_type_id"`
+	Result         string                 `json:"result"`
+	Started        PulpTime               `json:"started"`
+	Completed      PulpTime               `json:"completed"`
+	AddedCount     int                    `json:"added_count"`
+	UpdatedCount   int                    `json:"updated_count"`
+	RemovedCount   int                    `json:"removed_count"`
+	ErrorMessage   string                 `json:"error_message"`
+	Exception      string                 `json:"exception"`
+	Traceback      string                 `json:"traceback"`
+	Summary        map[string]interface{} `json:"summary"`
+	Details        map[string]interface{} `json:"details"`
+}
+
+func (r SyncResult) String() string {
+	return Stringify(r)
+}
+
+// SyncResult decodes the sync report returned by a finished sync task.
+func (t *Task) SyncResult() (*SyncResult, error) {
+	if len(t.RawResult) == 0 || string(t.RawResult) == "null" {
+		return nil, fmt.Errorf("pulp: task %s has no result", t.Id)
+	}
+
+	r := new(SyncResult)
+	if err := json.Unmarshal(t.RawResult, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SyncAndWait syncs the repository, waits for the sync task and returns its
+// result. An error wrapping ErrTaskFailed is returned with the result if
+// the sync did not succeed.
+func (s *RepositoriesService) SyncAndWait(repository string, poll *PollOptions) (*SyncResult, error) {
+	cr, _, err := s.SyncRepository(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := cr.WaitAll(s.client, poll)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range tasks {
+		if !t.HasTag(ActionTag("sync")) {
+			continue
+		}
+
+		r, err := t.SyncResult()
+		if err != nil {
+			return nil, err
+		}
+		if r.Result == ResultFailed || r.Result == ResultError {
+			return r, fmt.Errorf("%w: sync of %s: %s", ErrTaskFailed, repository, r.ErrorMessage)
+		}
+		return r, nil
+	}
+	return nil, fmt.Errorf("pulp: no sync task spawned for repository %s", repository)
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/repo/publish.html#retrieving-publish-history
+type PublishResult struct {
+	Id                string                 `json:"id"`
+	RepoId            string                 `json:"repo_id"`
+	DistributorId     string                 `json:"distributor_id"`
+	DistributorTypeId string                 `json:"distributor_type_id"`
+	Result            string                 `json:"result"`
+	Started           PulpTime               `json:"started"`
+	Completed         PulpTime               `json:"completed"`
+	ErrorMessage      string                 `json:"error_message"`
+	Exception         string                 `json:"exception"`
+	Traceback         string                 `json:"traceback"`
+	Summary           map[string]interface{} `json:"summary"`
+	Details           map[string]interface{} `json:"details"`
+}
+
+func (r PublishResult) String() string {
+	return Stringify(r)
+}
+
+func (s *RepositoriesService) GetSyncHistory(repository string, opt *HistoryOptions) ([]*SyncResult, *Response, error) {
+	u := fmt.Sprintf("repositories/%s/history/sync/", repository)
+
+	req, err := s.client.NewRequest("GET", u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var h []*SyncResult
+	resp, err := s.client.Do(req, &h)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return h, resp, err
+}
+
+func (s *RepositoriesService) GetPublishHistory(repository string, distributor string, opt *HistoryOptions) ([]*PublishResult, *Response, error) {
+	u := fmt.Sprintf("repositories/%s/history/publish/%s/", repository, distributor)
+
+	req, err := s.client.NewRequest("GET", u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var h []*PublishResult
+	resp, err := s.client.Do(req, &h)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return h, resp, err
+}
+
+// LastSync returns the most recent sync of the repository, or nil if it
+// was never synced.
+func (s *RepositoriesService) LastSync(repository string) (*SyncResult, *Response, error) {
+	h, resp, err := s.GetSyncHistory(repository, &HistoryOptions{Limit: 1, Sort: SortDescending})
+	if err != nil || len(h) == 0 {
+		return nil, resp, err
+	}
+	return h[0], resp, nil
+}
@@ -0,0 +1,118 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// Hooks are called on every request sent by the client, including retries,
+// to plug in logging or tracing. OnError is called on network errors and on
+// pulp errors.
+type Hooks interface {
+	OnRequest(req *http.Request)
+	OnResponse(req *http.Request, resp *http.Response, elapsed time.Duration)
+	OnError(req *http.Request, err error)
+}
+
+// HookFuncs implements Hooks with optional functions.
+type HookFuncs struct {
+	Request  func(req *http.Request)
+	Response func(req *http.Request, resp *http.Response, elapsed time.Duration)
+	Error    func(req *http.Request, err error)
+}
+
+func (h *HookFuncs) OnRequest(req *http.Request) {
+	if h.Request != nil {
+		h.Request(req)
+	}
+}
+
+func (h *HookFuncs) OnResponse(req *http.Request, resp *http.Response, elapsed time.Duration) {
+	if h.Response != nil {
+		h.Response(req, resp, elapsed)
+	}
+}
+
+func (h *HookFuncs) OnError(req *http.Request, err error) {
+	if h.Error != nil {
+		h.Error(req, err)
+	}
+}
+
+// NewLogHooks logs the requests with a standard logger.
+func NewLogHooks(l *log.Logger) Hooks {
+	return &HookFuncs{
+		Request: func(req *http.Request) {
+			l.Printf("pulp: %s %s", req.Method, requestPath(req))
+		},
+		Response: func(req *http.Request, resp *http.Response, elapsed time.Duration) {
+			l.Printf("pulp: %s %s: %d (%v)", req.Method, requestPath(req), resp.StatusCode, elapsed)
+		},
+		Error: func(req *http.Request, err error) {
+			l.Printf("pulp: %s %s: %v", req.Method, requestPath(req), err)
+		},
+	}
+}
+
+func requestPath(req *http.Request) string {
+	if req.URL.Opaque != "" {
+		return req.URL.Opaque
+	}
+	return req.URL.Path
+}
+
+// WithHooks adds hooks to the client.
+func WithHooks(hooks ...Hooks) ClientOption {
+	return func(c *Client) error {
+		c.AddHooks(hooks...)
+		return nil
+	}
+}
+
+func (c *Client) AddHooks(hooks ...Hooks) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// copy so that the hooks being called are never modified
+	c.hooks = append(c.hooks[:len(c.hooks):len(c.hooks)], hooks...)
+}
+
+func (c *Client) getHooks() []Hooks {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.hooks
+}
+
+func (c *Client) onRequest(req *http.Request) {
+	for _, h := range c.getHooks() {
+		h.OnRequest(req)
+	}
+}
+
+func (c *Client) onResponse(req *http.Request, resp *http.Response, elapsed time.Duration) {
+	for _, h := range c.getHooks() {
+		h.OnResponse(req, resp, elapsed)
+	}
+}
+
+func (c *Client) onError(req *http.Request, err error) {
+	for _, h := range c.getHooks() {
+		h.OnError(req, err)
+	}
+}
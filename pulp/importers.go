@@ -1,31 +1,287 @@
 package pulp
 
-import ()
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	YumImporterType    = "yum_importer"
+	DockerImporterType = "docker_importer"
+	IsoImporterType    = "iso_importer"
+	PuppetImporterType = "puppet_importer"
+	PythonImporterType = "python_importer"
+	OstreeImporterType = "ostree_web_importer"
+)
 
 type Importer struct {
 	Id             string          `json:"id"`
+	ImporterTypeId string          `json:"importer_type_id"`
+	RepoId         string          `json:"repo_id"`
+	LastSync       PulpTime        `json:"last_sync"`
+	LastUpdated    PulpTime        `json:"last_updated"`
+	ScheduledSyncs []string        `json:"scheduled_syncs"`
+	Href           string          `json:"_href"`
 	ImporterConfig *ImporterConfig `json:"config"`
-	Content        *Content        `json:"content"`
-	Metadata       *Metadata       `json:"metadata"`
+
+	// the complete config, including the plugin specific fields
+	Config map[string]interface{} `json:"-"`
+
+	// the fields unknown to the client, encoded again with the importer
+	ExtraFields map[string]json.RawMessage `json:"-"`
+}
+
+func (i Importer) String() string {
+	return Stringify(i)
+}
+
+func (i *Importer) UnmarshalJSON(data []byte) error {
+	type importer Importer
+	if err := json.Unmarshal(data, (*importer)(i)); err != nil {
+		return err
+	}
+
+	var c struct {
+		Config map[string]interface{} `json:"config"`
+	}
+	err := json.Unmarshal(data, &c)
+	if err != nil {
+		return err
+	}
+	i.Config = c.Config
+
+	i.ExtraFields, err = extraFields(data, i)
+	return err
+}
+
+// MarshalJSON encodes the complete Config, with the fields of
+// ImporterConfig set again in case they were changed.
+func (i Importer) MarshalJSON() ([]byte, error) {
+	config, err := i.config()
+	if err != nil {
+		return nil, err
+	}
+
+	type importer Importer
+	return marshalWithExtra(struct {
+		importer
+		Config map[string]interface{} `json:"config"`
+	}{importer(i), config}, i.ExtraFields)
+}
+
+// config returns Config with the fields of ImporterConfig, the empty ones
+// only if Config has them too, so that no value the server did not send is
+// added.
+func (i *Importer) config() (map[string]interface{}, error) {
+	if i.ImporterConfig == nil {
+		return i.Config, nil
+	}
+
+	data, err := json.Marshal(i.ImporterConfig)
+	if err != nil {
+		return nil, err
+	}
+	var typed map[string]interface{}
+	if err := json.Unmarshal(data, &typed); err != nil {
+		return nil, err
+	}
+
+	config := make(map[string]interface{}, len(i.Config)+len(typed))
+	for k, v := range i.Config {
+		config[k] = v
+	}
+	for k, v := range typed {
+		if _, ok := config[k]; ok || v != "" && v != false {
+			config[k] = v
+		}
+	}
+	return config, nil
+}
+
+// DecodeConfig decodes the config of the importer into one of the typed
+// configs, e.g. a *YumImporterConfig.
+func (i *Importer) DecodeConfig(v interface{}) error {
+	return decodeConfig(i.Config, v)
+}
+
+// decodeConfig decodes a plugin config into v through json.
+func decodeConfig(config map[string]interface{}, v interface{}) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
 }
 
 type ImporterConfig struct {
 	Feed          string `json:"feed"`
 	RemoveMissing bool   `json:"remove_missing"`
+	SslCaCert     string `json:"ssl_ca_cert"`
+	SslClientCert string `json:"ssl_client_cert"`
+	SslClientKey  string `json:"ssl_client_key"`
+}
+
+func (c ImporterConfig) String() string {
+	return Stringify(c)
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/plugins/pulp_rpm/tech-reference/yum-plugins.html#yum-importer
+type YumImporterConfig struct {
+	Feed              string   `json:"feed,omitempty"`
+	SslCaCert         string   `json:"ssl_ca_cert,omitempty"`
+	SslClientCert     string   `json:"ssl_client_cert,omitempty"`
+	SslClientKey      string   `json:"ssl_client_key,omitempty"`
+	SslValidation     *bool    `json:"ssl_validation,omitempty"`
+	ProxyHost         string   `json:"proxy_host,omitempty"`
+	ProxyPort         int      `json:"proxy_port,omitempty"`
+	ProxyUsername     string   `json:"proxy_username,omitempty"`
+	ProxyPassword     string   `json:"proxy_password,omitempty"`
+	BasicAuthUsername string   `json:"basic_auth_username,omitempty"`
+	BasicAuthPassword string   `json:"basic_auth_password,omitempty"`
+	MaxDownloads      int      `json:"max_downloads,omitempty"`
+	MaxSpeed          int      `json:"max_speed,omitempty"`
+	Validate          *bool    `json:"validate,omitempty"`
+	RemoveMissing     *bool    `json:"remove_missing,omitempty"`
+	RetainOldCount    int      `json:"retain_old_count,omitempty"`
+	Skip              []string `json:"skip,omitempty"`
+	DownloadPolicy    string   `json:"download_policy,omitempty"`
+}
+
+func (c YumImporterConfig) String() string {
+	return Stringify(c)
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/plugins/pulp_docker/tech-reference/importer.html
+type DockerImporterConfig struct {
+	Feed          string   `json:"feed,omitempty"`
+	UpstreamName  string   `json:"upstream_name,omitempty"`
+	EnableV1      *bool    `json:"enable_v1,omitempty"`
+	EnableV2      *bool    `json:"enable_v2,omitempty"`
+	MaskId        string   `json:"mask_id,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	SslValidation *bool    `json:"ssl_validation,omitempty"`
+	MaxDownloads  int      `json:"max_downloads,omitempty"`
+	ProxyHost     string   `json:"proxy_host,omitempty"`
+	ProxyPort     int      `json:"proxy_port,omitempty"`
+	ProxyUsername string   `json:"proxy_username,omitempty"`
+	ProxyPassword string   `json:"proxy_password,omitempty"`
+}
+
+func (c DockerImporterConfig) String() string {
+	return Stringify(c)
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/plugins/pulp_rpm/tech-reference/iso-plugins.html#iso-importer
+type IsoImporterConfig struct {
+	Feed          string `json:"feed,omitempty"`
+	SslCaCert     string `json:"ssl_ca_cert,omitempty"`
+	SslClientCert string `json:"ssl_client_cert,omitempty"`
+	SslClientKey  string `json:"ssl_client_key,omitempty"`
+	SslValidation *bool  `json:"ssl_validation,omitempty"`
+	ProxyHost     string `json:"proxy_host,omitempty"`
+	ProxyPort     int    `json:"proxy_port,omitempty"`
+	ProxyUsername string `json:"proxy_username,omitempty"`
+	ProxyPassword string `json:"proxy_password,omitempty"`
+	MaxDownloads  int    `json:"max_downloads,omitempty"`
+	MaxSpeed      int    `json:"max_speed,omitempty"`
+	RemoveMissing *bool  `json:"remove_missing,omitempty"`
+	ValidateUnits *bool  `json:"validate,omitempty"`
+}
+
+func (c IsoImporterConfig) String() string {
+	return Stringify(c)
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/plugins/pulp_puppet/tech-reference/plugin_conf.html#importer
+type PuppetImporterConfig struct {
+	Feed          string   `json:"feed,omitempty"`
+	Queries       []string `json:"queries,omitempty"`
+	RemoveMissing *bool    `json:"remove_missing,omitempty"`
 }
 
-// included in task
-type Content struct {
-	State        string   `json:"state"`
-	ItemsTotal   int      `json:"items_total"`
-	ItemsLeft    int      `json:"items_left"`
-	SizeTotal    int      `json:"size_total"`
-	SizeLeft     int      `json:"size_left"`
-	ErrorDetails []string `json:"error_details"`
+func (c PuppetImporterConfig) String() string {
+	return Stringify(c)
 }
 
-// included in task
-type Metadata struct {
-	State string
-	Error string
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/repo/cud.html#associate-an-importer-to-a-repository
+func (s *RepositoriesService) ListImporters(repository string) ([]*Importer, *Response, error) {
+	u := fmt.Sprintf("repositories/%s/importers/", repository)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var i []*Importer
+	resp, err := s.client.Do(req, &i)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return i, resp, err
+}
+
+func (s *RepositoriesService) GetImporter(repository string, importer string) (*Importer, *Response, error) {
+	u := fmt.Sprintf("repositories/%s/importers/%s/", repository, importer)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	i := new(Importer)
+	resp, err := s.client.Do(req, i)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return i, resp, err
+}
+
+// ImporterConfig takes one of the typed plugin configs, e.g. a
+// *YumImporterConfig, or a map for plugins not covered by the library.
+type AddImporterOptions struct {
+	ImporterTypeId string      `json:"importer_type_id"`
+	ImporterConfig interface{} `json:"importer_config,omitempty"`
+}
+
+func (s *RepositoriesService) AddImporter(repository string, opt *AddImporterOptions) (*CallReport, *Response, error) {
+	u := fmt.Sprintf("repositories/%s/importers/", repository)
+	return s.importerAction("POST", u, opt)
+}
+
+type updateImporterRequest struct {
+	ImporterConfig interface{} `json:"importer_config"`
+}
+
+// only the keys set in config are updated, a nil value resets a key to its
+// default
+func (s *RepositoriesService) UpdateImporter(repository string, importer string, config interface{}) (*CallReport, *Response, error) {
+	u := fmt.Sprintf("repositories/%s/importers/%s/", repository, importer)
+	return s.importerAction("PUT", u, &updateImporterRequest{ImporterConfig: config})
+}
+
+func (s *RepositoriesService) RemoveImporter(repository string, importer string) (*CallReport, *Response, error) {
+	u := fmt.Sprintf("repositories/%s/importers/%s/", repository, importer)
+	return s.importerAction("DELETE", u, nil)
+}
+
+func (s *RepositoriesService) importerAction(method string, u string, opt interface{}) (*CallReport, *Response, error) {
+	req, err := s.client.NewRequest(method, u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cr := new(CallReport)
+	resp, err := s.client.Do(req, cr)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return cr, resp, err
 }
@@ -0,0 +1,250 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ParseRepoFile parses the repositories of a yum .repo file. The [main]
+// section is skipped.
+func ParseRepoFile(r io.Reader) ([]*YumRepo, error) {
+	var repos []*YumRepo
+	var repo *YumRepo
+	var key string
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";"):
+			continue
+
+		case strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]"):
+			id := strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			repo, key = nil, ""
+			if id != "main" {
+				repo = &YumRepo{Id: id, Name: id, Enabled: true, SslVerify: true}
+				repos = append(repos, repo)
+			}
+			continue
+		}
+
+		// continuation lines of multi-valued keys, like baseurl
+		if line[0] == ' ' || line[0] == '\t' {
+			if repo != nil && key != "" {
+				repo.set(key, trimmed)
+			}
+			continue
+		}
+
+		i := strings.Index(line, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("pulp: line %d of the repo file: %q is not a key=value", n, line)
+		}
+		key = strings.ToLower(strings.TrimSpace(line[:i]))
+		if repo != nil {
+			repo.set(key, strings.TrimSpace(line[i+1:]))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// set sets a key of the .repo file section.
+func (r *YumRepo) set(key string, value string) {
+	switch key {
+	case "name":
+		r.Name = value
+	case "baseurl":
+		r.BaseURLs = append(r.BaseURLs, strings.Fields(strings.Replace(value, ",", " ", -1))...)
+	case "mirrorlist":
+		r.MirrorList = value
+	case "enabled":
+		r.Enabled = repoFileBool(value)
+	case "gpgcheck":
+		r.GpgCheck = repoFileBool(value)
+	case "gpgkey":
+		r.GpgKeys = append(r.GpgKeys, strings.Fields(strings.Replace(value, ",", " ", -1))...)
+	case "sslverify":
+		r.SslVerify = repoFileBool(value)
+	case "sslcacert":
+		r.SslCaCert = value
+	case "sslclientcert":
+		r.SslClientCert = value
+	case "sslclientkey":
+		r.SslClientKey = value
+	}
+}
+
+func repoFileBool(value string) bool {
+	switch strings.ToLower(value) {
+	case "1", "yes", "true", "on":
+		return true
+	}
+	return false
+}
+
+// ParseMirrorList parses a mirror list, one url per line.
+func ParseMirrorList(r io.Reader) ([]string, error) {
+	var urls []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		u, err := url.Parse(line)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("pulp: %q of the mirror list is not an url", line)
+		}
+		urls = append(urls, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
+
+var invalidRepoIdChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// MirrorListRepos returns a repository per url of a mirror list, with an
+// id made of the host and path of the url, e.g. mirror.example.com-centos-7.
+func MirrorListRepos(urls []string) []*YumRepo {
+	repos := make([]*YumRepo, 0, len(urls))
+	for _, rawurl := range urls {
+		id := rawurl
+		if u, err := url.Parse(rawurl); err == nil {
+			id = u.Host + u.Path
+		}
+		id = strings.Trim(invalidRepoIdChars.ReplaceAllString(id, "-"), "-")
+
+		repos = append(repos, &YumRepo{Id: id, Name: id, BaseURLs: []string{rawurl}, Enabled: true, SslVerify: true})
+	}
+	return repos
+}
+
+type IngestOptions struct {
+	// values of the yum variables, e.g. {"releasever": "7", "basearch": "x86_64"}
+	Vars map[string]string
+
+	// prepended to the ids of the repositories
+	IdPrefix string
+
+	// also create the disabled repositories
+	IncludeDisabled bool
+
+	// create a yum distributor publishing each repository over https at
+	// its id
+	Distributor bool
+}
+
+// IngestYumRepos creates a pulp rpm repository with a yum importer for each
+// of the repositories, syncing from the first baseurl or the mirror list.
+// The certificates of the repositories are read from their files. The
+// error is a *BulkError listing the repositories which were not created.
+func (s *RepositoriesService) IngestYumRepos(repos []*YumRepo, opt *IngestOptions) ([]*Repository, error) {
+	if opt == nil {
+		opt = &IngestOptions{}
+	}
+
+	var created []*Repository
+	errs := make(map[string]error)
+
+	for _, yr := range repos {
+		if !yr.Enabled && !opt.IncludeDisabled {
+			continue
+		}
+
+		createOpt, err := yr.createOptions(opt)
+		if err != nil {
+			errs[yr.Id] = err
+			continue
+		}
+
+		r, _, err := s.CreateRepository(createOpt)
+		if err != nil {
+			errs[yr.Id] = err
+			continue
+		}
+		created = append(created, r)
+	}
+
+	if len(errs) > 0 {
+		return created, &BulkError{Errors: errs}
+	}
+	return created, nil
+}
+
+func (r *YumRepo) createOptions(opt *IngestOptions) (*CreateRepositoryOptions, error) {
+	feed := r.MirrorList
+	if len(r.BaseURLs) > 0 {
+		feed = r.BaseURLs[0]
+	}
+	if feed == "" {
+		return nil, fmt.Errorf("pulp: repository %s has no baseurl nor mirrorlist", r.Id)
+	}
+
+	importerConfig := &YumImporterConfig{Feed: expandContentPath(feed, opt.Vars)}
+	if !r.SslVerify {
+		importerConfig.SslValidation = Bool(false)
+	}
+	if r.SslCaCert != "" || r.SslClientCert != "" {
+		fc, err := LoadFeedCertificates(r.SslCaCert, r.SslClientCert, r.SslClientKey)
+		if err != nil {
+			return nil, err
+		}
+		if err := importerConfig.SetFeedCertificates(fc); err != nil {
+			return nil, err
+		}
+	}
+
+	id := opt.IdPrefix + r.Id
+	createOpt := &CreateRepositoryOptions{
+		Id:             id,
+		DisplayName:    expandContentPath(r.Name, opt.Vars),
+		Notes:          map[string]string{RepoTypeNote: RpmRepoType},
+		ImporterTypeId: YumImporterType,
+		ImporterConfig: importerConfig,
+	}
+
+	if opt.Distributor {
+		createOpt.Distributors = []*AddDistributorOptions{{
+			DistributorId:     YumDistributorType,
+			DistributorTypeId: YumDistributorType,
+			DistributorConfig: &YumDistributorConfig{RelativeUrl: id, Https: true},
+			AutoPublish:       true,
+		}}
+	}
+	return createOpt, nil
+}
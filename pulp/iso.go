@@ -0,0 +1,113 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"io"
+	"path"
+	"strings"
+)
+
+// IsoContentPath is where the iso distributor publishes the files of a
+// repository over http(s), the relative url defaults to the repository id.
+func IsoContentPath(relativeUrl string) string {
+	return "/pulp/isos/" + strings.Trim(relativeUrl, "/") + "/"
+}
+
+// ListIsos returns the files of an iso repository.
+func (s *UnitsService) ListIsos(repository string) ([]*IsoUnit, *Response, error) {
+	if err := s.client.requireContentType(IsoUnitType, "pulp_rpm"); err != nil {
+		return nil, nil, err
+	}
+
+	units, resp, err := s.SearchUnits(repository, NewUnitCriteria(IsoUnitType))
+	if err != nil {
+		return nil, resp, err
+	}
+
+	var isos []*IsoUnit
+	for _, u := range units {
+		if iso := u.Iso(); iso != nil {
+			isos = append(isos, iso)
+		}
+	}
+	return isos, resp, err
+}
+
+// UploadIso uploads the content of r as the file name into an iso
+// repository. The sha256 checksum and the size of the unit are computed
+// while uploading. It waits for the import to finish and deletes the upload
+// request.
+func (s *UnitsService) UploadIso(repository string, name string, r io.Reader, poll *PollOptions) (*IsoUnit, error) {
+	if err := s.client.requireContentType(IsoUnitType, "pulp_rpm"); err != nil {
+		return nil, err
+	}
+
+	iso := &IsoUnit{Name: name}
+
+	_, err := s.uploadAndImport(repository, IsoUnitType, r, func(cr *ChecksumReader) interface{} {
+		iso.Checksum, iso.Size = cr.Sum(ChecksumSha256), cr.Size()
+		return map[string]interface{}{
+			"name":     iso.Name,
+			"checksum": iso.Checksum,
+			"size":     iso.Size,
+		}
+	}, poll)
+	if err != nil {
+		return nil, err
+	}
+	return iso, nil
+}
+
+// uploadAndImport uploads the content of r and imports it as a unit of the
+// type, with the unit key built from the sha256, sha1 and md5 checksums and
+// the size of the content. It waits for the import and deletes the upload
+// request.
+func (s *UnitsService) uploadAndImport(repository string, typeId string, r io.Reader, unitKey func(*ChecksumReader) interface{}, poll *PollOptions) ([]*Task, error) {
+	sums, err := NewChecksumReader(r, ChecksumSha256, ChecksumSha1, ChecksumMd5)
+	if err != nil {
+		return nil, err
+	}
+
+	upload, _, err := s.client.Uploads.Upload(sums, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer s.client.Uploads.DeleteUpload(upload)
+
+	cr, _, err := s.ImportUpload(repository, &ImportUploadOptions{
+		UploadId:   String(upload),
+		UnitTypeId: typeId,
+		UnitKey:    unitKey(sums),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cr.WaitAll(s.client, poll)
+}
+
+// DownloadIso writes a file published by the iso distributor of the
+// repository to w. The relative url of the distributor defaults to the
+// repository id.
+func (c *Client) DownloadIso(relativeUrl string, name string, w io.Writer) (*Response, error) {
+	req, err := c.NewContentRequest("GET", path.Join(IsoContentPath(relativeUrl), name))
+	if err != nil {
+		return nil, err
+	}
+	return c.Download(req, w)
+}
@@ -0,0 +1,114 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+const defaultUnitPageSize = 1000
+
+// ListUnitsPaged returns a page of the units of the repository matching the
+// criteria. The units are sorted by association unless the criteria define
+// a sort, so that pages do not overlap.
+func (s *UnitsService) ListUnitsPaged(repository string, criteria *UnitCriteria, skip int, limit int) ([]*Unit, *Response, error) {
+	c := NewUnitCriteria()
+	if criteria != nil {
+		cp := *criteria
+		c = &cp
+	}
+
+	if c.Sort == nil {
+		c.Sort = &UnitSort{Association: []SortField{{Field: "unit_id", Direction: SortAscending}}}
+	}
+	c.Skip = skip
+	c.Limit = limit
+
+	return s.SearchUnits(repository, c)
+}
+
+// UnitIterator iterates over the units of a repository, fetching them a
+// page at a time:
+//
+//	it := client.Units.NewUnitIterator("my-repo", pulp.NewUnitCriteria("rpm"), 0)
+//	for it.Next() {
+//		u := it.Unit()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type UnitIterator struct {
+	service    *UnitsService
+	repository string
+	criteria   *UnitCriteria
+	pageSize   int
+
+	page []*Unit
+	skip int
+	unit *Unit
+	last bool
+	err  error
+}
+
+// NewUnitIterator iterates over the units matching the criteria, a
+// pageSize of 0 fetches 1000 units per request.
+func (s *UnitsService) NewUnitIterator(repository string, criteria *UnitCriteria, pageSize int) *UnitIterator {
+	if pageSize <= 0 {
+		pageSize = defaultUnitPageSize
+	}
+	return &UnitIterator{
+		service:    s,
+		repository: repository,
+		criteria:   criteria,
+		pageSize:   pageSize,
+	}
+}
+
+// Next advances to the next unit, it returns false at the end of the units
+// or on error.
+func (it *UnitIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if len(it.page) == 0 {
+		if it.last {
+			it.unit = nil
+			return false
+		}
+
+		it.page, _, it.err = it.service.ListUnitsPaged(it.repository, it.criteria, it.skip, it.pageSize)
+		if it.err != nil {
+			return false
+		}
+
+		it.skip += len(it.page)
+		it.last = len(it.page) < it.pageSize
+		if len(it.page) == 0 {
+			it.unit = nil
+			return false
+		}
+	}
+
+	it.unit, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+func (it *UnitIterator) Unit() *Unit {
+	return it.unit
+}
+
+func (it *UnitIterator) Err() error {
+	return it.err
+}
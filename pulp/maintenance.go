@@ -0,0 +1,95 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"fmt"
+)
+
+// states of the tasks which are not done yet
+var pendingTaskStates = []string{TaskWaiting, TaskAccepted, TaskRunning}
+
+// WaitForIdle polls the tasks until none is waiting or running anymore.
+// It returns the tasks still pending with an ErrTimeout error when the
+// timeout expires.
+func (s *TasksService) WaitForIdle(opt *PollOptions) ([]*Task, error) {
+	var tasks []*Task
+	err := s.client.poll(opt, func() (done bool, err error) {
+		tasks, _, err = s.SearchTasks(&TaskSearchCriteria{States: pendingTaskStates})
+		return len(tasks) == 0, err
+	})
+
+	switch {
+	case err == ErrTimeout:
+		return tasks, fmt.Errorf("%w waiting for %d tasks to finish", ErrTimeout, len(tasks))
+	case err != nil:
+		return nil, err
+	}
+	return nil, nil
+}
+
+type QuiesceOptions struct {
+	// also pause the install, update and uninstall schedules of the consumers
+	Consumers bool
+
+	// polling of the running tasks, a zero Timeout waits until they are done
+	PollOptions
+}
+
+// Maintenance holds the schedules paused by Quiesce.
+type Maintenance struct {
+	client *Client
+	Paused []*ScheduledAction
+}
+
+// Quiesce prepares the server for maintenance: it disables all the enabled
+// sync and publish schedules and waits for the running tasks to finish.
+//
+// The returned Maintenance is set as soon as schedules are paused, also on
+// error, so that they can be resumed.
+func (c *Client) Quiesce(opt *QuiesceOptions) (*Maintenance, error) {
+	if opt == nil {
+		opt = &QuiesceOptions{}
+	}
+
+	schedules, err := c.Schedules.ListSchedules(&ListSchedulesOptions{Consumers: opt.Consumers})
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Maintenance{client: c}
+	for _, s := range schedules {
+		if s.Enabled {
+			m.Paused = append(m.Paused, s)
+		}
+	}
+
+	if err := c.Schedules.SetEnabled(m.Paused, false); err != nil {
+		return m, err
+	}
+
+	if _, err := c.Tasks.WaitForIdle(&opt.PollOptions); err != nil {
+		return m, err
+	}
+
+	return m, nil
+}
+
+// Resume enables the schedules paused by Quiesce again.
+func (m *Maintenance) Resume() error {
+	return m.client.Schedules.SetEnabled(m.Paused, true)
+}
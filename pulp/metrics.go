@@ -0,0 +1,185 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Metrics receives the measures of the client, to export them to a
+// monitoring system. An adapter for prometheus could look like
+//
+//	type promMetrics struct {
+//		requests *prometheus.HistogramVec // labels: method, endpoint, code
+//		errors   *prometheus.CounterVec   // labels: method, endpoint
+//		tasks    prometheus.Gauge
+//	}
+//
+//	func (m *promMetrics) ObserveRequest(method, endpoint string, code int, elapsed time.Duration) {
+//		m.requests.WithLabelValues(method, endpoint, strconv.Itoa(code)).Observe(elapsed.Seconds())
+//	}
+//
+//	func (m *promMetrics) ObserveError(method, endpoint string) {
+//		m.errors.WithLabelValues(method, endpoint).Inc()
+//	}
+//
+//	func (m *promMetrics) AddInFlightTasks(delta int) {
+//		m.tasks.Add(float64(delta))
+//	}
+//
+// The endpoint is the path of the request with the ids replaced by ":id",
+// like "repositories/:id/actions/sync/", to keep the number of label values
+// low.
+type Metrics interface {
+	// ObserveRequest is called for each response received, whatever its
+	// status code.
+	ObserveRequest(method, endpoint string, code int, elapsed time.Duration)
+
+	// ObserveError is called when no response was received.
+	ObserveError(method, endpoint string)
+
+	// AddInFlightTasks is called with 1 when the client starts waiting for
+	// a task and with -1 when it stops.
+	AddInFlightTasks(delta int)
+}
+
+// WithMetrics reports the requests and the waited tasks of the client to m.
+func WithMetrics(m Metrics) ClientOption {
+	return func(c *Client) error {
+		c.SetMetrics(m)
+		return nil
+	}
+}
+
+func (c *Client) SetMetrics(m Metrics) {
+	c.mu.Lock()
+	c.metrics = m
+	hooked := c.metricsHooked
+	c.metricsHooked = true
+	c.mu.Unlock()
+
+	// the hook reads the metrics of the client, install it once
+	if !hooked {
+		c.AddHooks(&metricsHooks{client: c})
+	}
+}
+
+func (c *Client) getMetrics() Metrics {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.metrics
+}
+
+// metricsHooks forward the requests to the metrics of the client, if any.
+type metricsHooks struct {
+	client *Client
+}
+
+func (h *metricsHooks) OnRequest(req *http.Request) {}
+
+func (h *metricsHooks) OnResponse(req *http.Request, resp *http.Response, elapsed time.Duration) {
+	if m := h.client.getMetrics(); m != nil {
+		m.ObserveRequest(req.Method, h.client.endpoint(req), resp.StatusCode, elapsed)
+	}
+}
+
+func (h *metricsHooks) OnError(req *http.Request, err error) {
+	// pulp errors come with a response, which was already observed
+	var er *ErrorResponse
+	if errors.As(err, &er) {
+		return
+	}
+	if m := h.client.getMetrics(); m != nil {
+		m.ObserveError(req.Method, h.client.endpoint(req))
+	}
+}
+
+func (c *Client) addInFlightTasks(delta int) {
+	if m := c.getMetrics(); m != nil {
+		m.AddInFlightTasks(delta)
+	}
+}
+
+// idCollections are the path segments followed by the id of a resource.
+var idCollections = map[string]bool{
+	"bindings":        true,
+	"catalog":         true,
+	"consumer_groups": true,
+	"consumers":       true,
+	"distributors":    true,
+	"event_listeners": true,
+	"importers":       true,
+	"repo_groups":     true,
+	"repositories":    true,
+	"roles":           true,
+	"sources":         true,
+	"task_groups":     true,
+	"tasks":           true,
+	"uploads":         true,
+	"users":           true,
+}
+
+// kindCollections are the path segments followed by a kind and then by the
+// id of a resource, like schedules/sync/<id>/ or orphans/rpm/<id>/. The
+// consumer schedules have a two segments kind, like
+// schedules/content/install/<id>/.
+var kindCollections = map[string]bool{
+	"orphans":   true,
+	"schedules": true,
+}
+
+// notIds are the path segments following a collection which are not ids.
+var notIds = map[string]bool{
+	"actions": true,
+	"search":  true,
+}
+
+// endpoint returns the path of the request relative to the api, with the
+// ids replaced by ":id". Paths outside of the api, like published content,
+// are reduced to their first two segments.
+func (c *Client) endpoint(req *http.Request) string {
+	path := requestPath(req)
+
+	apiPath := c.apiURL().Path
+	if !strings.HasPrefix(path, apiPath) {
+		segments := strings.SplitN(strings.Trim(path, "/"), "/", 3)
+		if len(segments) > 2 {
+			segments = segments[:2]
+		}
+		return "/" + strings.Join(segments, "/") + "/"
+	}
+
+	segments := strings.Split(strings.TrimPrefix(path, apiPath), "/")
+	for i := 1; i < len(segments); i++ {
+		switch {
+		case segments[i] == "" || notIds[segments[i]]:
+		case idCollections[segments[i-1]]:
+			segments[i] = ":id"
+		case kindCollections[segments[i-1]]:
+			if segments[i] == "content" {
+				i++
+			}
+			if i++; i < len(segments) && segments[i] != "" {
+				segments[i] = ":id"
+			}
+		}
+	}
+	return strings.Join(segments, "/")
+}
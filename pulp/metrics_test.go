@@ -0,0 +1,55 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestEndpoint(t *testing.T) {
+	client, err := NewClient("pulp.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"/pulp/api/v2/repositories/":                                              "repositories/",
+		"/pulp/api/v2/repositories/zoo/":                                          "repositories/:id/",
+		"/pulp/api/v2/repositories/search/":                                       "repositories/search/",
+		"/pulp/api/v2/repositories/zoo/actions/sync/":                             "repositories/:id/actions/sync/",
+		"/pulp/api/v2/repositories/zoo/search/units/":                             "repositories/:id/search/units/",
+		"/pulp/api/v2/tasks/search/":                                              "tasks/search/",
+		"/pulp/api/v2/tasks/0fe4fcab/":                                            "tasks/:id/",
+		"/pulp/api/v2/repositories/zoo/importers/yum_importer/schedules/sync/":    "repositories/:id/importers/:id/schedules/sync/",
+		"/pulp/api/v2/repositories/zoo/importers/yum_importer/schedules/sync/42/": "repositories/:id/importers/:id/schedules/sync/:id/",
+		"/pulp/api/v2/consumers/c1/schedules/content/install/42/":                 "consumers/:id/schedules/content/install/:id/",
+		"/pulp/api/v2/content/orphans/rpm/":                                       "content/orphans/rpm/",
+		"/pulp/api/v2/content/orphans/rpm/1b2c/":                                  "content/orphans/rpm/:id/",
+		"/pulp/repos/zoo/os/Packages/a.rpm":                                       "/pulp/repos/",
+	}
+
+	for path, want := range tests {
+		req, err := http.NewRequest("GET", "https://pulp.example.com"+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := client.endpoint(req); got != want {
+			t.Errorf("endpoint(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
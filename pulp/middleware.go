@@ -0,0 +1,97 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"net/http"
+)
+
+// Middleware wraps the transport of the client, to add headers, sign
+// requests or record them, e.g.
+//
+//	client.Use(func(next http.RoundTripper) http.RoundTripper {
+//		return pulp.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+//			req = req.Clone(req.Context())
+//			req.Header.Set("X-Request-Id", newRequestId())
+//			return next.RoundTrip(req)
+//		})
+//	})
+//
+// A middleware sees every attempt of a retried request.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to a http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// WithMiddleware adds middlewares to the client, see Use.
+func WithMiddleware(middlewares ...Middleware) ClientOption {
+	return func(c *Client) error {
+		c.Use(middlewares...)
+		return nil
+	}
+}
+
+// Use adds middlewares around the transport of the client. The middlewares
+// are called in the order they were added, the first one seeing the request
+// first and the response last.
+func (c *Client) Use(middlewares ...Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// copy so that a client sharing the middlewares is not modified
+	c.middlewares = append(c.middlewares[:len(c.middlewares):len(c.middlewares)], middlewares...)
+	c.rebuild()
+}
+
+// rebuild wraps the base transport in the response cache and the
+// middlewares, and installs the chain in a copy of the http client, so that
+// requests in flight keep using the previous one. c.mu must be held.
+func (c *Client) rebuild() {
+	var rt http.RoundTripper = c.transport
+	if c.transport == nil {
+		rt = c.base
+	}
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	if c.cache != nil {
+		// below the middlewares, which see every request
+		c.cache = c.cache.withTransport(rt)
+		rt = c.cache
+	}
+
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		rt = c.middlewares[i](rt)
+	}
+
+	hc := *c.client
+	hc.Transport = rt
+	c.client = &hc
+}
+
+// httpClient returns the http client sending the requests, which must not
+// be modified.
+func (c *Client) httpClient() *http.Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.client
+}
@@ -0,0 +1,121 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+// Pulp nodes are child pulp servers, registered as consumers of the parent,
+// which sync the repositories bound to them from the parent.
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/user-guide/nodes.html
+const (
+	NodesHttpDistributorType = "nodes_http_distributor"
+	NodesHttpImporterType    = "nodes_http_importer"
+
+	// unit type ids of the node sync
+	NodeUnitType       = "node"
+	RepositoryUnitType = "repository"
+)
+
+// update strategies of a node or of a repository bound to a node
+const (
+	NodeStrategyAdditive = "additive"
+	NodeStrategyMirror   = "mirror"
+)
+
+const (
+	childNodeNote          = "_child-node"
+	nodeUpdateStrategyNote = "_node-update-strategy"
+)
+
+// IsChildNode reports if the consumer is an activated child node.
+func (c *Consumer) IsChildNode() bool {
+	v, _ := c.Notes[childNodeNote].(bool)
+	return v
+}
+
+// ActivateNode activates the consumer as a child node. The strategy
+// defaults to NodeStrategyAdditive.
+func (s *ConsumersService) ActivateNode(consumer string, strategy string) (*Consumer, *Response, error) {
+	if strategy == "" {
+		strategy = NodeStrategyAdditive
+	}
+	return s.UpdateConsumer(consumer, &UpdateConsumerOptions{
+		Notes: map[string]interface{}{
+			childNodeNote:          true,
+			nodeUpdateStrategyNote: strategy,
+		},
+	})
+}
+
+func (s *ConsumersService) DeactivateNode(consumer string) (*Consumer, *Response, error) {
+	return s.UpdateConsumer(consumer, &UpdateConsumerOptions{
+		Notes: map[string]interface{}{
+			childNodeNote:          nil,
+			nodeUpdateStrategyNote: nil,
+		},
+	})
+}
+
+// EnableNodeRepository adds the nodes distributor to the repository, which
+// publishes it for the child nodes.
+func (s *RepositoriesService) EnableNodeRepository(repository string) (*Distributor, *Response, error) {
+	return s.AddDistributor(repository, &AddDistributorOptions{
+		DistributorId:     NodesHttpDistributorType,
+		DistributorTypeId: NodesHttpDistributorType,
+		DistributorConfig: map[string]interface{}{},
+		AutoPublish:       true,
+	})
+}
+
+func (s *RepositoriesService) DisableNodeRepository(repository string) (*CallReport, *Response, error) {
+	return s.RemoveDistributor(repository, NodesHttpDistributorType)
+}
+
+// BindNode binds a repository enabled for nodes to the child node. The
+// strategy defaults to NodeStrategyAdditive.
+func (s *ConsumersService) BindNode(consumer string, repository string, strategy string) (*CallReport, *Response, error) {
+	if strategy == "" {
+		strategy = NodeStrategyAdditive
+	}
+	return s.Bind(consumer, &BindOptions{
+		RepoId:        repository,
+		DistributorId: NodesHttpDistributorType,
+		NotifyAgent:   Bool(false),
+		BindingConfig: map[string]interface{}{"strategy": strategy},
+	})
+}
+
+func (s *ConsumersService) UnbindNode(consumer string, repository string) (*CallReport, *Response, error) {
+	return s.Unbind(consumer, repository, NodesHttpDistributorType)
+}
+
+// SyncNode triggers the sync of all the repositories bound to the child
+// node.
+func (s *ConsumersService) SyncNode(consumer string) (*CallReport, *Response, error) {
+	units := []*ConsumerUnit{{TypeId: NodeUnitType}}
+	return s.contentAction(consumer, "update", units, nil)
+}
+
+// SyncNodeRepository triggers the sync of a repository bound to the child
+// node.
+func (s *ConsumersService) SyncNodeRepository(consumer string, repository string) (*CallReport, *Response, error) {
+	units := []*ConsumerUnit{{
+		TypeId:  RepositoryUnitType,
+		UnitKey: map[string]string{"repo_id": repository},
+	}}
+	return s.contentAction(consumer, "update", units, nil)
+}
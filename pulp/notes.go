@@ -0,0 +1,102 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"encoding/json"
+)
+
+// RepoTypeNote is the note pulp-admin sets to tell the type of a repository.
+const RepoTypeNote = "_repo-type"
+
+// The values of the _repo-type note set by the pulp-admin extensions.
+const (
+	RpmRepoType    = "rpm-repo"
+	IsoRepoType    = "iso-repo"
+	DockerRepoType = "docker-repo"
+	PuppetRepoType = "puppet-repo"
+	PythonRepoType = "PYTHON"
+	OstreeRepoType = "OSTREE"
+	DebRepoType    = "deb-repo"
+)
+
+// RepoType returns the _repo-type note of the repository, if any.
+func (r *Repository) RepoType() string {
+	return r.Notes[RepoTypeNote]
+}
+
+// GetNotes returns the notes of the repository.
+func (s *RepositoriesService) GetNotes(repository string) (map[string]string, *Response, error) {
+	r, resp, err := s.GetRepository(repository, nil)
+	if err != nil {
+		return nil, resp, err
+	}
+	return r.Notes, resp, err
+}
+
+// SetNotes sets notes on the repository, leaving the others untouched, and
+// returns the updated repository.
+func (s *RepositoriesService) SetNotes(repository string, notes map[string]string) (*Repository, *Response, error) {
+	delta := make(map[string]interface{}, len(notes))
+	for k, v := range notes {
+		delta[k] = v
+	}
+	return s.updateNotes(repository, delta)
+}
+
+func (s *RepositoriesService) SetNote(repository string, key string, value string) (*Repository, *Response, error) {
+	return s.updateNotes(repository, map[string]interface{}{key: value})
+}
+
+func (s *RepositoriesService) DeleteNote(repository string, key string) (*Repository, *Response, error) {
+	return s.updateNotes(repository, map[string]interface{}{key: nil})
+}
+
+// SetRepoType sets the _repo-type note of the repository, e.g. to
+// RpmRepoType for pulp-admin to list it with the rpm repositories.
+func (s *RepositoriesService) SetRepoType(repository string, repoType string) (*Repository, *Response, error) {
+	return s.SetNote(repository, RepoTypeNote, repoType)
+}
+
+// ListRepositoriesByType returns the repositories having the _repo-type
+// note set to repoType.
+func (s *RepositoriesService) ListRepositoriesByType(repoType string) ([]*Repository, *Response, error) {
+	return s.SearchRepositories(&SearchRepositoriesOptions{
+		Criteria: NewCriteria().Where(NoteFilter(RepoTypeNote, repoType)),
+	})
+}
+
+// updateNotes updates the notes, a nil value removes a note. The update of
+// the notes alone is not run in a task, the call report holds the updated
+// repository.
+func (s *RepositoriesService) updateNotes(repository string, notes map[string]interface{}) (*Repository, *Response, error) {
+	cr, resp, err := s.UpdateRepository(repository, &UpdateRepositoryOptions{Notes: notes})
+	if err != nil {
+		return nil, resp, err
+	}
+	if cr.Error != nil {
+		return nil, resp, cr.Error
+	}
+
+	r := new(Repository)
+	if len(cr.Result) > 0 {
+		if err := json.Unmarshal(cr.Result, r); err != nil {
+			return nil, resp, err
+		}
+	}
+	return r, resp, nil
+}
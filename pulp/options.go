@@ -0,0 +1,130 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ClientOption configures the client on creation.
+type ClientOption func(*Client) error
+
+// WithHTTPClient makes the client send its requests with a copy of
+// httpClient. When its transport is a *http.Transport, the client uses a
+// clone of it, which the options configuring the transport change, e.g.
+// WithTLSConfig; httpClient and its transport are never modified.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) error {
+		if httpClient == nil {
+			return errors.New("pulp: nil http client")
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		hc := *httpClient
+		c.client = &hc
+		c.transport, c.base = nil, nil
+		switch t := hc.Transport.(type) {
+		case nil:
+			c.transport = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			c.transport = t.Clone()
+		default:
+			c.base = t
+		}
+		c.rebuild()
+		return nil
+	}
+}
+
+// WithTransport makes the client send its requests with a clone of
+// transport instead of DefaultTransport.
+func WithTransport(transport *http.Transport) ClientOption {
+	return func(c *Client) error {
+		if transport == nil {
+			return errors.New("pulp: nil transport")
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.transport, c.base = transport.Clone(), nil
+		c.rebuild()
+		return nil
+	}
+}
+
+func WithBasicAuth(user string, passwd string) ClientOption {
+	return func(c *Client) error {
+		c.SetCredentials(user, passwd)
+		return nil
+	}
+}
+
+// WithTLSConfig replaces the tls configuration of the transport.
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return func(c *Client) error {
+		err := c.updateTransport(func(t *http.Transport) error {
+			t.TLSClientConfig = config.Clone()
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		c.InsecureSkipVerify = config != nil && config.InsecureSkipVerify
+		return nil
+	}
+}
+
+// WithInsecureSkipVerify disables the verification of the server
+// certificate.
+func WithInsecureSkipVerify() ClientOption {
+	return func(c *Client) error {
+		return c.DisableSSLVerification()
+	}
+}
+
+// WithoutSSL sends the requests over plain http.
+func WithoutSSL() ClientOption {
+	return func(c *Client) error {
+		c.DisableSsl = true
+		return nil
+	}
+}
+
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) error {
+		c.UserAgent = userAgent
+		return nil
+	}
+}
+
+// WithTimeout bounds the time a request may take, retries included, 2
+// seconds by default. 0 disables the timeout. Downloads are not bounded.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) error {
+		c.mu.Lock()
+		c.timeout = timeout
+		c.mu.Unlock()
+		return nil
+	}
+}
@@ -0,0 +1,116 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type OrphansService struct {
+	client *Client
+}
+
+// Orphan is a content unit which is not associated with any repository.
+// Metadata holds all the type specific fields of the unit.
+type Orphan struct {
+	Id            string                 `json:"_id"`
+	ContentTypeId string                 `json:"_content_type_id"`
+	Href          string                 `json:"_href"`
+	StoragePath   string                 `json:"_storage_path"`
+	Metadata      map[string]interface{} `json:"-"`
+}
+
+func (o Orphan) String() string {
+	return Stringify(o)
+}
+
+func (o *Orphan) UnmarshalJSON(data []byte) error {
+	type orphan Orphan
+	if err := json.Unmarshal(data, (*orphan)(o)); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &o.Metadata)
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/content/orphan.html
+func (s *OrphansService) ListOrphans(contentType string) ([]*Orphan, *Response, error) {
+	u := fmt.Sprintf("content/orphans/%s/", contentType)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var o []*Orphan
+	resp, err := s.client.Do(req, &o)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return o, resp, err
+}
+
+func (s *OrphansService) GetOrphan(contentType string, id string) (*Orphan, *Response, error) {
+	u := fmt.Sprintf("content/orphans/%s/%s/", contentType, id)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	o := new(Orphan)
+	resp, err := s.client.Do(req, o)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return o, resp, err
+}
+
+// the orphan is removed by a spawned task
+func (s *OrphansService) DeleteOrphan(contentType string, id string) (*CallReport, *Response, error) {
+	u := fmt.Sprintf("content/orphans/%s/%s/", contentType, id)
+	return s.deleteOrphans(u)
+}
+
+// the orphans of the content type are removed by a spawned task
+func (s *OrphansService) DeleteOrphansOfType(contentType string) (*CallReport, *Response, error) {
+	u := fmt.Sprintf("content/orphans/%s/", contentType)
+	return s.deleteOrphans(u)
+}
+
+// the orphans are removed by a spawned task
+func (s *OrphansService) DeleteAllOrphans() (*CallReport, *Response, error) {
+	return s.deleteOrphans("content/orphans/")
+}
+
+func (s *OrphansService) deleteOrphans(u string) (*CallReport, *Response, error) {
+	req, err := s.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cr := new(CallReport)
+	resp, err := s.client.Do(req, cr)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return cr, resp, err
+}
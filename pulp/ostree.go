@@ -0,0 +1,126 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	OstreeUnitType        = "ostree"
+	OstreeDistributorType = "ostree_web_distributor"
+)
+
+func init() {
+	RegisterUnitType(OstreeUnitType, func() interface{} { return new(OstreeBranchUnit) })
+}
+
+// Pulp Api docs:
+// http://pulp-ostree.readthedocs.io/en/latest/tech-reference/importer.html
+type OstreeImporterConfig struct {
+	Feed          string   `json:"feed,omitempty"`
+	Branches      []string `json:"branches,omitempty"`
+	Depth         *int     `json:"depth,omitempty"`
+	GpgKeys       []string `json:"gpg_keys,omitempty"`
+	SslCaCert     string   `json:"ssl_ca_cert,omitempty"`
+	SslClientCert string   `json:"ssl_client_cert,omitempty"`
+	SslClientKey  string   `json:"ssl_client_key,omitempty"`
+	SslValidation *bool    `json:"ssl_validation,omitempty"`
+	ProxyHost     string   `json:"proxy_host,omitempty"`
+	ProxyPort     int      `json:"proxy_port,omitempty"`
+	ProxyUsername string   `json:"proxy_username,omitempty"`
+	ProxyPassword string   `json:"proxy_password,omitempty"`
+}
+
+func (c OstreeImporterConfig) String() string {
+	return Stringify(c)
+}
+
+// Pulp Api docs:
+// http://pulp-ostree.readthedocs.io/en/latest/tech-reference/distributor.html
+type OstreeDistributorConfig struct {
+	RelativePath string `json:"relative_path,omitempty"`
+	Depth        *int   `json:"depth,omitempty"`
+}
+
+// OstreeBranchUnit is a commit of a branch of an ostree repository.
+//
+// Pulp Api docs:
+// http://pulp-ostree.readthedocs.io/en/latest/tech-reference/branch.html
+type OstreeBranchUnit struct {
+	UnitMetadata
+	RemoteId string                 `json:"remote_id"`
+	Branch   string                 `json:"branch"`
+	Commit   string                 `json:"commit"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+func (u *Unit) OstreeBranch() *OstreeBranchUnit {
+	m, _ := u.Metadata.(*OstreeBranchUnit)
+	return m
+}
+
+// OstreeContentPath returns the path of a published ostree repository.
+func OstreeContentPath(relativePath string) string {
+	return "/pulp/ostree/web/" + strings.Trim(relativePath, "/") + "/"
+}
+
+// ListOstreeBranches returns the branch commits of the ostree repository.
+func (s *UnitsService) ListOstreeBranches(repository string) ([]*OstreeBranchUnit, *Response, error) {
+	if err := s.client.requireContentType(OstreeUnitType, "pulp_ostree"); err != nil {
+		return nil, nil, err
+	}
+
+	units, resp, err := s.SearchUnits(repository, NewUnitCriteria(OstreeUnitType))
+	if err != nil {
+		return nil, resp, err
+	}
+
+	var branches []*OstreeBranchUnit
+	for _, u := range units {
+		if b := u.OstreeBranch(); b != nil {
+			branches = append(branches, b)
+		}
+	}
+	return branches, resp, err
+}
+
+// OstreeBranchNames returns the sorted names of the branches of the commits.
+func OstreeBranchNames(branches []*OstreeBranchUnit) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, b := range branches {
+		if !seen[b.Branch] {
+			seen[b.Branch] = true
+			names = append(names, b.Branch)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SyncOstreeBranches syncs the ostree repository, limited to the given
+// branches, e.g. "fedora-atomic/f23/x86_64/docker-host". The configured
+// branches of the importer are left untouched.
+func (s *RepositoriesService) SyncOstreeBranches(repository string, branches ...string) (*CallReport, *Response, error) {
+	if err := s.client.requireContentType(OstreeUnitType, "pulp_ostree"); err != nil {
+		return nil, nil, err
+	}
+
+	return s.SyncRepositoryWithConfig(repository, &OstreeImporterConfig{Branches: branches})
+}
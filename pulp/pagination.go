@@ -0,0 +1,131 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+)
+
+// ErrNoNextPage is returned by Response.NextPage after the last page.
+var ErrNoNextPage = errors.New("pulp: no next page")
+
+// pageInfo is the paging of a search, as sent in its criteria.
+type pageInfo struct {
+	Criteria *struct {
+		Limit int `json:"limit"`
+		Skip  int `json:"skip"`
+	} `json:"criteria"`
+}
+
+// setPage fills the paging of the response to a search sent with criteria
+// having a limit, once its results were decoded in v.
+func (c *Client) setPage(r *Response, req *http.Request, v interface{}) {
+	if req.Method != "POST" || req.GetBody == nil {
+		return
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return
+	}
+
+	body, err := requestBody(req)
+	if err != nil {
+		return
+	}
+
+	var p pageInfo
+	if err := json.Unmarshal(body, &p); err != nil || p.Criteria == nil || p.Criteria.Limit <= 0 {
+		return
+	}
+
+	r.client = c
+	r.request = req
+	r.Limit = p.Criteria.Limit
+	r.Skip = p.Criteria.Skip
+	r.Count = rv.Elem().Len()
+	if r.Count == r.Limit {
+		r.NextSkip = r.Skip + r.Limit
+	}
+}
+
+// NextPage re-issues the search of the response with its skip advanced by
+// its limit and decodes the results in v. Searches without limit, like
+// ListUnits with a zero Limit, return all the results in a single page.
+//
+//	units, resp, err := client.Units.ListUnits("repo", &pulp.ListUnitsOptions{Limit: 100})
+//	for err == nil && resp.NextSkip > 0 {
+//		var page []*pulp.Unit
+//		if resp, err = resp.NextPage(&page); err == nil {
+//			units = append(units, page...)
+//		}
+//	}
+//
+// Pulp does not report the total number of results of a search, the last
+// page is the first one holding less than limit results. The unit counts of
+// a repository give the totals of its units.
+func (r *Response) NextPage(v interface{}) (*Response, error) {
+	if r.NextSkip == 0 || r.request == nil {
+		return nil, ErrNoNextPage
+	}
+
+	body, err := requestBody(r.request)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, err
+	}
+	var criteria map[string]interface{}
+	if err := json.Unmarshal(doc["criteria"], &criteria); err != nil {
+		return nil, err
+	}
+
+	criteria["skip"] = r.NextSkip
+	if doc["criteria"], err = json.Marshal(criteria); err != nil {
+		return nil, err
+	}
+	if body, err = json.Marshal(doc); err != nil {
+		return nil, err
+	}
+
+	req := r.request.Clone(r.request.Context())
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.ContentLength = int64(len(body))
+
+	return r.client.Do(req, v)
+}
+
+func requestBody(req *http.Request) ([]byte, error) {
+	rc, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
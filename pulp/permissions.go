@@ -0,0 +1,116 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import ()
+
+type PermissionsService struct {
+	client *Client
+}
+
+// operations which can be granted on a resource
+const (
+	PermissionCreate  = "CREATE"
+	PermissionRead    = "READ"
+	PermissionUpdate  = "UPDATE"
+	PermissionDelete  = "DELETE"
+	PermissionExecute = "EXECUTE"
+)
+
+// Permission lists the operations granted to each user on a resource
+// (e.g. /v2/repositories/).
+type Permission struct {
+	Id       string              `json:"id"`
+	Resource string              `json:"resource"`
+	Users    map[string][]string `json:"users"`
+	Href     string              `json:"_href"`
+}
+
+func (p Permission) String() string {
+	return Stringify(p)
+}
+
+type ListPermissionsOptions struct {
+	Resource string `url:"resource,omitempty" json:"resource,omitempty"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/permission/retrieval.html
+func (s *PermissionsService) ListPermissions(opt *ListPermissionsOptions) ([]*Permission, *Response, error) {
+	req, err := s.client.NewRequest("GET", "permissions/", opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var p []*Permission
+	resp, err := s.client.Do(req, &p)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return p, resp, err
+}
+
+type PermissionOptions struct {
+	Login      string   `json:"login,omitempty"`
+	RoleId     string   `json:"role_id,omitempty"`
+	Resource   string   `json:"resource"`
+	Operations []string `json:"operations"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/permission/grant_revoke.html
+func (s *PermissionsService) GrantToUser(login string, resource string, operations []string) (*Response, error) {
+	return s.permissionAction("grant_to_user", &PermissionOptions{
+		Login:      login,
+		Resource:   resource,
+		Operations: operations,
+	})
+}
+
+func (s *PermissionsService) RevokeFromUser(login string, resource string, operations []string) (*Response, error) {
+	return s.permissionAction("revoke_from_user", &PermissionOptions{
+		Login:      login,
+		Resource:   resource,
+		Operations: operations,
+	})
+}
+
+func (s *PermissionsService) GrantToRole(role string, resource string, operations []string) (*Response, error) {
+	return s.permissionAction("grant_to_role", &PermissionOptions{
+		RoleId:     role,
+		Resource:   resource,
+		Operations: operations,
+	})
+}
+
+func (s *PermissionsService) RevokeFromRole(role string, resource string, operations []string) (*Response, error) {
+	return s.permissionAction("revoke_from_role", &PermissionOptions{
+		RoleId:     role,
+		Resource:   resource,
+		Operations: operations,
+	})
+}
+
+func (s *PermissionsService) permissionAction(action string, opt *PermissionOptions) (*Response, error) {
+	req, err := s.client.NewRequest("POST", "permissions/actions/"+action+"/", opt)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
@@ -0,0 +1,298 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// states of a progress section or step
+const (
+	ProgressNotStarted = "NOT_STARTED"
+	ProgressInProgress = "IN_PROGRESS"
+	ProgressFinished   = "FINISHED"
+	ProgressFailed     = "FAILED"
+	ProgressSkipped    = "SKIPPED"
+	ProgressCancelled  = "CANCELLED"
+)
+
+// ProgressReport is the progress of a sync or publish task. Importers
+// report their progress under their type id, distributors under their id
+// as a list of steps.
+type ProgressReport struct {
+	YumImporter    *SyncProgress `json:"yum_importer"`
+	DockerImporter *SyncProgress `json:"docker_importer"`
+
+	// sync progress by importer type id, e.g. puppet_importer
+	Importers map[string]*SyncProgress `json:"-"`
+
+	// publish steps by distributor id
+	Publish map[string][]*ProgressStep `json:"-"`
+}
+
+func (p *ProgressReport) UnmarshalJSON(data []byte) error {
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return err
+	}
+
+	*p = ProgressReport{}
+	for name, raw := range sections {
+		if bytes.Equal(raw, []byte("null")) {
+			continue
+		}
+		if strings.HasSuffix(name, "_importer") {
+			sp := new(SyncProgress)
+			if err := json.Unmarshal(raw, sp); err != nil {
+				return err
+			}
+			if p.Importers == nil {
+				p.Importers = make(map[string]*SyncProgress)
+			}
+			p.Importers[name] = sp
+			continue
+		}
+
+		if !isJSONArray(raw) {
+			continue
+		}
+		var steps []*ProgressStep
+		if err := json.Unmarshal(raw, &steps); err != nil {
+			return err
+		}
+		if p.Publish == nil {
+			p.Publish = make(map[string][]*ProgressStep)
+		}
+		p.Publish[name] = steps
+	}
+
+	p.YumImporter = p.Importers[YumImporterType]
+	p.DockerImporter = p.Importers[DockerImporterType]
+	return nil
+}
+
+// Importer returns the sync progress of the importer type, which may be
+// given with or without the _importer suffix, or nil.
+func (p *ProgressReport) Importer(importerType string) *SyncProgress {
+	if !strings.HasSuffix(importerType, "_importer") {
+		importerType += "_importer"
+	}
+	return p.Importers[importerType]
+}
+
+// ImporterTypes returns the sorted type ids of the importers reporting
+// progress.
+func (p *ProgressReport) ImporterTypes() []string {
+	var types []string
+	for t := range p.Importers {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Distributor returns the publish steps of the distributor, or nil.
+func (p *ProgressReport) Distributor(distributorId string) []*ProgressStep {
+	return p.Publish[distributorId]
+}
+
+// SyncProgress is the progress of an importer. The yum and puppet importers
+// report named sections, the iso importer reports its counters at the top
+// level and step based importers like docker and ostree report Steps.
+type SyncProgress struct {
+	State string `json:"state"`
+
+	Metadata        *ProgressSection `json:"metadata"`
+	Content         *ProgressSection `json:"content"`
+	Comps           *ProgressSection `json:"comps"`
+	Errata          *ProgressSection `json:"errata"`
+	Distribution    *ProgressSection `json:"distribution"`
+	PurgeDuplicates *ProgressSection `json:"purge_duplicates"`
+	Modules         *ProgressSection `json:"modules"`
+
+	// iso importer
+	NumIsos          int           `json:"num_isos"`
+	NumIsosFinished  int           `json:"num_isos_finished"`
+	TotalBytes       int64         `json:"total_bytes"`
+	FinishedBytes    int64         `json:"finished_bytes"`
+	IsoErrorMessages []interface{} `json:"iso_error_messages"`
+
+	Steps []*ProgressStep `json:"-"`
+}
+
+func (p *SyncProgress) UnmarshalJSON(data []byte) error {
+	if isJSONArray(data) {
+		*p = SyncProgress{}
+		return json.Unmarshal(data, &p.Steps)
+	}
+
+	type syncProgress SyncProgress
+	return json.Unmarshal(data, (*syncProgress)(p))
+}
+
+// sections returns the named sections in the order they are run
+func (p *SyncProgress) sections() []struct {
+	name    string
+	section *ProgressSection
+} {
+	return []struct {
+		name    string
+		section *ProgressSection
+	}{
+		{"metadata", p.Metadata},
+		{"content", p.Content},
+		{"comps", p.Comps},
+		{"errata", p.Errata},
+		{"distribution", p.Distribution},
+		{"purge_duplicates", p.PurgeDuplicates},
+		{"modules", p.Modules},
+	}
+}
+
+type ProgressSection struct {
+	State        string                 `json:"state"`
+	ItemsTotal   int                    `json:"items_total"`
+	ItemsLeft    int                    `json:"items_left"`
+	SizeTotal    int64                  `json:"size_total"`
+	SizeLeft     int64                  `json:"size_left"`
+	Error        string                 `json:"error"`
+	ErrorDetails []interface{}          `json:"error_details"`
+	Details      map[string]interface{} `json:"details"`
+
+	// puppet importer
+	TotalCount    int `json:"total_count"`
+	FinishedCount int `json:"finished_count"`
+	ErrorCount    int `json:"error_count"`
+}
+
+// total and done items of the section
+func (s *ProgressSection) items() (int, int) {
+	if s.ItemsTotal > 0 {
+		return s.ItemsTotal, s.ItemsTotal - s.ItemsLeft
+	}
+	return s.TotalCount, s.FinishedCount + s.ErrorCount
+}
+
+// ProgressStep is a step of a publish, or of a step based sync.
+type ProgressStep struct {
+	StepId       string        `json:"step_id"`
+	StepType     string        `json:"step_type"`
+	Description  string        `json:"description"`
+	State        string        `json:"state"`
+	ItemsTotal   int           `json:"items_total"`
+	NumProcessed int           `json:"num_processed"`
+	NumSuccess   int           `json:"num_success"`
+	NumFailures  int           `json:"num_failures"`
+	ErrorDetails []interface{} `json:"error_details"`
+}
+
+// PercentComplete estimates the progress of the task from the items
+// processed by its sync or publish steps.
+func (t *Task) PercentComplete() float64 {
+	if t.State == TaskFinished {
+		return 100
+	}
+
+	var total, done int
+	for _, p := range t.syncProgress() {
+		for _, s := range p.sections() {
+			if s.section != nil {
+				t, d := s.section.items()
+				total += t
+				done += d
+			}
+		}
+		total += p.NumIsos
+		done += p.NumIsosFinished
+		total, done = addSteps(p.Steps, total, done)
+	}
+	for _, steps := range t.ProgressReport.Publish {
+		total, done = addSteps(steps, total, done)
+	}
+
+	if total == 0 {
+		return 0
+	}
+	return float64(done) * 100 / float64(total)
+}
+
+func addSteps(steps []*ProgressStep, total int, done int) (int, int) {
+	for _, s := range steps {
+		if s.ItemsTotal > 0 {
+			total += s.ItemsTotal
+			done += s.NumProcessed
+		}
+	}
+	return total, done
+}
+
+// CurrentStep returns the name of the section or the description of the
+// step in progress, or the next one to run.
+func (t *Task) CurrentStep() string {
+	next := ""
+	check := func(name string, state string) bool {
+		switch state {
+		case ProgressInProgress:
+			return true
+		case ProgressNotStarted, "":
+			if next == "" {
+				next = name
+			}
+		}
+		return false
+	}
+
+	for _, p := range t.syncProgress() {
+		for _, s := range p.sections() {
+			if s.section != nil && check(s.name, s.section.State) {
+				return s.name
+			}
+		}
+		for _, s := range p.Steps {
+			if check(s.Description, s.State) {
+				return s.Description
+			}
+		}
+		if p.NumIsos > 0 && check("isos", p.State) {
+			return "isos"
+		}
+	}
+	for _, steps := range t.ProgressReport.Publish {
+		for _, s := range steps {
+			if check(s.Description, s.State) {
+				return s.Description
+			}
+		}
+	}
+	return next
+}
+
+func (t *Task) syncProgress() []*SyncProgress {
+	var p []*SyncProgress
+	for _, importerType := range t.ProgressReport.ImporterTypes() {
+		p = append(p, t.ProgressReport.Importers[importerType])
+	}
+	return p
+}
+
+func isJSONArray(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '['
+}
@@ -0,0 +1,159 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"fmt"
+)
+
+// Promotion is an ordered chain of repositories, e.g. dev, staging and
+// prod. Promoting a stage copies the units of the previous stage which are
+// missing from it:
+//
+//	p := pulp.NewPromotion(client, "dev", "staging", "prod")
+//	p.Publish = true
+//	report, err := p.Promote("staging")
+type Promotion struct {
+	client *Client
+
+	// repository ids, from the first to the last stage
+	Stages []string
+
+	// promotes only the units of these types, all by default
+	TypeIds []string
+
+	// publishes the promoted stage with all its distributors
+	Publish bool
+
+	// polls the copy and publish tasks
+	Poll *PollOptions
+}
+
+func NewPromotion(client *Client, stages ...string) *Promotion {
+	return &Promotion{client: client, Stages: stages}
+}
+
+// PromotionReport lists the units copied, or to be copied for a dry run,
+// from Source to Destination.
+type PromotionReport struct {
+	Source      string
+	Destination string
+	Units       []*Unit
+	Tasks       []*Task
+	DryRun      bool
+}
+
+func (r PromotionReport) String() string {
+	return Stringify(r)
+}
+
+// Plan is a dry run of Promote, it returns the units which would be
+// copied into the stage without changing any repository.
+func (p *Promotion) Plan(stage string) (*PromotionReport, error) {
+	source, err := p.previous(stage)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &PromotionReport{Source: source, Destination: stage, DryRun: true}
+
+	present := make(map[string]bool)
+	err = p.eachUnit(stage, NewUnitCriteria(p.TypeIds...).SelectUnitFields("_id"), func(u *Unit) {
+		present[u.UnitId] = true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.eachUnit(source, NewUnitCriteria(p.TypeIds...), func(u *Unit) {
+		if !present[u.UnitId] {
+			r.Units = append(r.Units, u)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Promote copies the units of the previous stage missing from the stage,
+// and publishes the stage if Publish is set. Nothing is published when no
+// unit is missing.
+func (p *Promotion) Promote(stage string) (*PromotionReport, error) {
+	r, err := p.Plan(stage)
+	if err != nil {
+		return nil, err
+	}
+	r.DryRun = false
+
+	if len(r.Units) == 0 {
+		return r, nil
+	}
+
+	var ids []string
+	for _, u := range r.Units {
+		ids = append(ids, u.UnitId)
+	}
+
+	for _, batch := range unitIdBatches(ids) {
+		criteria := NewUnitCriteria(p.TypeIds...).WhereUnit(In("_id", Strings(batch)...))
+
+		cr, _, err := p.client.Units.CopyUnits(r.Source, stage, criteria, nil)
+		if err != nil {
+			return r, err
+		}
+		if err := p.wait(r, cr); err != nil {
+			return r, err
+		}
+	}
+
+	if p.Publish {
+		tasks, err := p.client.Repositories.publishAndWait(stage, nil, p.Poll)
+		r.Tasks = append(r.Tasks, tasks...)
+		if err != nil {
+			return r, err
+		}
+	}
+
+	return r, nil
+}
+
+func (p *Promotion) wait(r *PromotionReport, cr *CallReport) error {
+	tasks, err := cr.WaitAll(p.client, p.Poll)
+	r.Tasks = append(r.Tasks, tasks...)
+	return err
+}
+
+func (p *Promotion) previous(stage string) (string, error) {
+	for i, s := range p.Stages {
+		if s == stage {
+			if i == 0 {
+				return "", fmt.Errorf("pulp: %s is the first stage of the promotion", stage)
+			}
+			return p.Stages[i-1], nil
+		}
+	}
+	return "", fmt.Errorf("pulp: %s is not a stage of the promotion", stage)
+}
+
+func (p *Promotion) eachUnit(repository string, criteria *UnitCriteria, fn func(*Unit)) error {
+	_, err := p.client.Units.StreamUnits(repository, criteria, func(u *Unit) error {
+		fn(u)
+		return nil
+	})
+	return err
+}
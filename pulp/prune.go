@@ -0,0 +1,112 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"errors"
+)
+
+// number of unit ids sent in a single copy or unassociate request
+const unitIdBatchSize = 500
+
+// PruneReport lists the units removed, or to be removed for a dry run, from
+// Repository.
+type PruneReport struct {
+	Repository string
+	Units      []*Unit
+	Tasks      []*Task
+	DryRun     bool
+}
+
+func (r PruneReport) String() string {
+	return Stringify(r)
+}
+
+// PruneRepository removes all but the keep newest versions of each package
+// of the repository, by name and arch. The typeIds default to rpm and
+// srpm, only these have versions. A dry run returns the units which would
+// be removed without removing them.
+func (s *RepositoriesService) PruneRepository(repository string, keep int, typeIds []string, dryRun bool) (*PruneReport, error) {
+	if keep < 1 {
+		return nil, errors.New("pulp: at least one version of each package must be kept")
+	}
+	if len(typeIds) == 0 {
+		if err := s.client.requireContentType(RpmUnitType, "pulp_rpm"); err != nil {
+			return nil, err
+		}
+		typeIds = []string{RpmUnitType, SrpmUnitType}
+	}
+
+	criteria := NewUnitCriteria(typeIds...).
+		SelectUnitFields("name", "epoch", "version", "release", "arch")
+
+	var units []*Unit
+	it := s.client.Units.NewUnitIterator(repository, criteria, 0)
+	for it.Next() {
+		units = append(units, it.Unit())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	r := &PruneReport{Repository: repository, DryRun: dryRun}
+
+	latest := make(map[*Unit]bool)
+	for _, u := range LatestVersions(units, keep) {
+		latest[u] = true
+	}
+	var ids []string
+	for _, u := range units {
+		if !latest[u] && u.NEVRA() != nil {
+			r.Units = append(r.Units, u)
+			ids = append(ids, u.UnitId)
+		}
+	}
+
+	if dryRun {
+		return r, nil
+	}
+
+	for _, batch := range unitIdBatches(ids) {
+		criteria := NewUnitCriteria(typeIds...).WhereUnit(In("_id", Strings(batch)...))
+
+		cr, _, err := s.client.Units.UnassociateUnits(repository, criteria)
+		if err != nil {
+			return r, err
+		}
+		tasks, err := cr.WaitAll(s.client, nil)
+		r.Tasks = append(r.Tasks, tasks...)
+		if err != nil {
+			return r, err
+		}
+	}
+	return r, nil
+}
+
+// unitIdBatches splits the ids in batches of unitIdBatchSize.
+func unitIdBatches(ids []string) [][]string {
+	var batches [][]string
+	for len(ids) > 0 {
+		n := len(ids)
+		if n > unitIdBatchSize {
+			n = unitIdBatchSize
+		}
+		batches = append(batches, ids[:n])
+		ids = ids[n:]
+	}
+	return batches
+}
@@ -18,15 +18,18 @@ package pulp
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"github.com/google/go-querystring/query"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -36,18 +39,57 @@ const (
 	userAgent      = "go-pulp/" + libraryVersion
 )
 
+// Client talks to a pulp server. It is safe for concurrent use, its
+// setters, like Use, SetTimeout or SetRetryPolicy, may be called while
+// requests are in flight and apply to the requests sent afterwards.
 type Client struct {
+	// mu guards the http client, the transport, baseURL, auth, hooks,
+	// timeout, retry, limiter and metrics. The http client and
+	// baseURL are replaced, never modified, so that a request can keep
+	// using the ones it read.
+	mu sync.RWMutex
+
+	// client is replaced, never modified. Its transport is the chain of
+	// the middlewares and the cache around transport, or around base when
+	// the transport is not a *http.Transport.
 	client             *http.Client
+	transport          *http.Transport
+	base               http.RoundTripper
+	cache              *ResponseCache
+	middlewares        []Middleware
 	DisableSsl         bool
 	InsecureSkipVerify bool
+	StrictChecksums    bool
 	baseURL            *url.URL
 	UserAgent          string
-	apiUser            string
-	apiPasswd          string
+	auth               AuthProvider
+	retry              *RetryPolicy
+	limiter            *RateLimiter
+	hooks              []Hooks
+	metrics            Metrics
+	metricsHooked      bool
+	server             *serverInfo
+	timeout            time.Duration
+	ctx                context.Context
 
 	// Services used for talking to different parts of the Pulp API.
-	Repositories *RepositoriesService
-	Tasks        *TasksService
+	ConsumerGroups *ConsumerGroupsService
+	Consumers      *ConsumersService
+	Content        *ContentService
+	ContentSources *ContentSourcesService
+	Docker         *DockerService
+	Events         *EventsService
+	Orphans        *OrphansService
+	Permissions    *PermissionsService
+	RepoGroups     *RepoGroupsService
+	Repositories   *RepositoriesService
+	Roles          *RolesService
+	Schedules      *SchedulesService
+	Status         *StatusService
+	Tasks          *TasksService
+	Units          *UnitsService
+	Uploads        *UploadsService
+	Users          *UsersService
 }
 
 type ListOptions struct {
@@ -55,58 +97,124 @@ type ListOptions struct {
 	PerPage int `url:"per_page,omitempty" json:"per_page,omitempty"`
 }
 
-func NewClient(host string, User string, Passwd string, DisableSsl bool, InsecureSkipVerify bool, httpClient *http.Client) (client *Client, err error) {
+// NewClient creates a client for the pulp server on host, e.g.
+//
+//	client, err := pulp.NewClient("pulp.example.com",
+//		pulp.WithBasicAuth("admin", "admin"),
+//		pulp.WithTimeout(10*time.Second),
+//	)
+//
+// Requests are sent over https unless WithoutSSL is given.
+func NewClient(host string, options ...ClientOption) (client *Client, err error) {
+	transport := DefaultTransport()
 
-	ssl := &tls.Config{}
-	if InsecureSkipVerify {
-		ssl.InsecureSkipVerify = true
+	client = &Client{
+		client: &http.Client{
+			Transport: transport,
+		},
+		transport: transport,
+		UserAgent: userAgent,
+		server:    new(serverInfo),
 	}
 
-	transport := &http.Transport{
-		TLSClientConfig: ssl,
-	}
+	// set default timeout on 2 seconds
+	client.SetTimeout(2000)
 
-	if httpClient == nil {
-		httpClient = &http.Client{
-			Transport: transport,
-			// Timeout:		time.Duration(1000) * time.Millisecond,
+	for _, option := range options {
+		if err := option(client); err != nil {
+			return nil, err
 		}
 	}
 
-	client = &Client{
-		client:             httpClient,
-		UserAgent:          userAgent,
-		apiUser:            User,
-		apiPasswd:          Passwd,
-		DisableSsl:         DisableSsl,
-		InsecureSkipVerify: InsecureSkipVerify,
+	if err := client.SetHost(host); err != nil {
+		return nil, err
 	}
 
-	// set default timeout on 2 seconds
-	client.SetTimeout(2000)
+	client.initServices()
+	return
+}
 
-	if err := client.SetHost(host); err != nil {
-		return nil, err
+func (c *Client) initServices() {
+	c.ConsumerGroups = &ConsumerGroupsService{client: c}
+	c.Consumers = &ConsumersService{client: c}
+	c.Content = &ContentService{client: c}
+	c.ContentSources = &ContentSourcesService{client: c}
+	c.Docker = &DockerService{client: c}
+	c.Events = &EventsService{client: c}
+	c.Orphans = &OrphansService{client: c}
+	c.Permissions = &PermissionsService{client: c}
+	c.RepoGroups = &RepoGroupsService{client: c}
+	c.Repositories = &RepositoriesService{client: c}
+	c.Roles = &RolesService{client: c}
+	c.Schedules = &SchedulesService{client: c}
+	c.Status = &StatusService{client: c}
+	c.Tasks = &TasksService{client: c}
+	c.Units = &UnitsService{client: c}
+	c.Uploads = &UploadsService{client: c}
+	c.Users = &UsersService{client: c}
+}
+
+// DefaultTransport returns the transport used by NewClient. It keeps more
+// idle connections per host than http.DefaultTransport so that polling many
+// tasks concurrently reuses connections instead of opening new ones.
+func DefaultTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       &tls.Config{},
+		TLSHandshakeTimeout:   10 * time.Second,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   20,
+		IdleConnTimeout:       90 * time.Second,
+		ResponseHeaderTimeout: 60 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
 	}
+}
 
-	client.Repositories = &RepositoriesService{client: client}
-	client.Tasks = &TasksService{client: client}
+// NewBasicAuthClient creates a client using basic authentication.
+//
+// Deprecated: use NewClient with WithBasicAuth, WithoutSSL,
+// WithInsecureSkipVerify and WithHTTPClient.
+func NewBasicAuthClient(host string, User string, Passwd string, DisableSsl bool, InsecureSkipVerify bool, httpClient *http.Client, options ...ClientOption) (*Client, error) {
+	var opts []ClientOption
+
+	if httpClient != nil {
+		opts = append(opts, WithHTTPClient(httpClient))
+	} else if InsecureSkipVerify {
+		opts = append(opts, WithInsecureSkipVerify())
+	}
 
-	return
+	if User != "" {
+		opts = append(opts, WithBasicAuth(User, Passwd))
+	}
+
+	if DisableSsl {
+		opts = append(opts, WithoutSSL())
+	}
+
+	return NewClient(host, append(opts, options...)...)
 }
 
-// set timeout in milliseconds
+// SetTimeout sets the timeout of the requests in milliseconds, 0 disables
+// it. See WithTimeout.
 func (c *Client) SetTimeout(timeout int) {
-	c.client.Timeout = time.Duration(timeout) * time.Millisecond
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timeout = time.Duration(timeout) * time.Millisecond
 }
 
 func (c *Client) SetHost(hostStr string) error {
 	var err error
 
 	p := "https"
+	c.mu.RLock()
 	if c.DisableSsl {
 		p = "http"
 	}
+	c.mu.RUnlock()
 
 	err = c.SetBaseURL(p + "://" + hostStr + "/pulp/api/" + apiVersion + "/")
 	if err != nil {
@@ -117,40 +225,133 @@ func (c *Client) SetHost(hostStr string) error {
 }
 
 func (c *Client) BaseURL() *url.URL {
-	u := *c.baseURL
+	u := *c.apiURL()
 	return &u
 }
 
+// apiURL returns the base url of the client, which must not be modified.
+func (c *Client) apiURL() *url.URL {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.baseURL
+}
+
 func (c *Client) SetBaseURL(urlStr string) error {
 	if !strings.HasSuffix(urlStr, "/") {
 		urlStr += "/"
 	}
 
-	var err error
-	c.baseURL, err = url.Parse(urlStr)
+	u, err := url.Parse(urlStr)
 	if err != nil {
 		return err
 	}
 
+	c.mu.Lock()
+	c.baseURL = u
+	c.mu.Unlock()
 	return nil
 }
 
+// Response wraps the http response. For searches sent with a limit, Limit
+// and Skip are the paging of the search, Count the number of results
+// received and NextSkip the skip of the next page, 0 after the last page.
 type Response struct {
 	*http.Response
+
+	Limit    int
+	Skip     int
+	Count    int
+	NextSkip int
+
+	client  *Client
+	request *http.Request
 }
 
+// NewRequest creates an api request. opt is encoded in the query string of
+// GET, HEAD and DELETE requests and sent as the json body of the others, use
+// NewRequestWithQuery to send both a query string and a body.
 func (c *Client) NewRequest(method, path string, opt interface{}) (*http.Request, error) {
-	u := *c.baseURL
-	// Set the encoded opaque data
-	u.Opaque = c.baseURL.Path + path
+	if method == "POST" || method == "PUT" || method == "PATCH" {
+		return c.NewRequestWithQuery(method, path, nil, opt)
+	}
+	return c.NewRequestWithQuery(method, path, opt, nil)
+}
 
-	q, err := query.Values(opt)
+// NewRequestWithQuery creates an api request with queryOpt, a struct with
+// url tags, encoded in the query string and body, if not nil, sent as json,
+// whatever the method.
+func (c *Client) NewRequestWithQuery(method, path string, queryOpt interface{}, body interface{}) (*http.Request, error) {
+	q, err := query.Values(queryOpt)
 	if err != nil {
 		return nil, err
 	}
+
+	req := c.newRequest(method, path, q)
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		setBody(req, data)
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	c.prepare(req)
+	return req, nil
+}
+
+// NewRequestWithBody creates an api request sending body as is, for
+// endpoints not wrapped by the library. The path may hold a query string.
+// The content type defaults to json. Bodies read from a *bytes.Buffer, a
+// *bytes.Reader or a *strings.Reader can be resent on retries.
+func (c *Client) NewRequestWithBody(method, path string, body io.Reader) (*http.Request, error) {
+	var q url.Values
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		var err error
+		if q, err = url.ParseQuery(path[i+1:]); err != nil {
+			return nil, err
+		}
+		path = path[:i]
+	}
+
+	req := c.newRequest(method, path, q)
+
+	if body != nil {
+		var data []byte
+		switch b := body.(type) {
+		case *bytes.Buffer:
+			data = b.Bytes()
+		case *bytes.Reader, *strings.Reader:
+			var err error
+			if data, err = ioutil.ReadAll(b); err != nil {
+				return nil, err
+			}
+		}
+
+		if data != nil {
+			setBody(req, data)
+		} else if rc, ok := body.(io.ReadCloser); ok {
+			req.Body = rc
+		} else {
+			req.Body = ioutil.NopCloser(body)
+		}
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	c.prepare(req)
+	return req, nil
+}
+
+// newRequest creates a request to the api path, without body.
+func (c *Client) newRequest(method, path string, q url.Values) *http.Request {
+	base := c.apiURL()
+	u := *base
+	// Set the encoded opaque data
+	u.Opaque = base.Path + path
 	u.RawQuery = q.Encode()
 
-	req := &http.Request{
+	return &http.Request{
 		Method:     method,
 		URL:        &u,
 		Proto:      "HTTP/1.1",
@@ -159,22 +360,69 @@ func (c *Client) NewRequest(method, path string, opt interface{}) (*http.Request
 		Header:     make(http.Header),
 		Host:       u.Host,
 	}
+}
 
-	if opt != nil && (method == "POST" || method == "PUT") {
-		bodyBytes, err := json.Marshal(opt)
+// setBody sets a body which can be resent on retries.
+func setBody(req *http.Request, data []byte) {
+	req.Body = ioutil.NopCloser(bytes.NewReader(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(data)), nil
+	}
+	req.ContentLength = int64(len(data))
+}
+
+// prepare sets the headers of an api request. It is authenticated when
+// sent, see AuthProvider.
+func (c *Client) prepare(req *http.Request) {
+	req.Header.Set("Accept", "application/json")
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+}
+
+// Raw sends a request to an api endpoint not wrapped by the library, with
+// the authentication, retries, hooks and error handling of the client. A
+// body which is not an io.Reader is sent as json. The response is decoded
+// as json into out, or copied to it if it is an io.Writer; out may be nil.
+//
+//	var types []map[string]interface{}
+//	_, err := client.Raw("GET", "plugins/types/", nil, &types)
+func (c *Client) Raw(method, path string, body interface{}, out interface{}) (*Response, error) {
+	var r io.Reader
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		r = b
+	default:
+		data, err := json.Marshal(body)
 		if err != nil {
 			return nil, err
 		}
-		bodyReader := bytes.NewReader(bodyBytes)
+		r = bytes.NewReader(data)
+	}
 
-		u.RawQuery = ""
-		req.Body = ioutil.NopCloser(bodyReader)
-		req.ContentLength = int64(bodyReader.Len())
-		req.Header.Set("Content-Type", "application/json")
+	req, err := c.NewRequestWithBody(method, path, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Do(req, out)
+}
+
+// NewContentRequest creates a request for content published by pulp outside
+// of the api, like /pulp/repos/. A path is resolved against the host of the
+// client.
+func (c *Client) NewContentRequest(method, urlStr string) (*http.Request, error) {
+	u, err := c.apiURL().Parse(urlStr)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return nil, err
 	}
 
-	req.Header.Set("Accept", "application/json")
-	req.SetBasicAuth(c.apiUser, c.apiPasswd)
 	if c.UserAgent != "" {
 		req.Header.Set("User-Agent", c.UserAgent)
 	}
@@ -182,13 +430,46 @@ func (c *Client) NewRequest(method, path string, opt interface{}) (*http.Request
 	return req, nil
 }
 
+// Download writes the body of a content request to w. Unlike Do it does not
+// apply the client timeout, which would abort the download of large files,
+// and the response is never cached.
+func (c *Client) Download(req *http.Request, w io.Writer) (*Response, error) {
+	req = withoutCache(c.withContext(req))
+
+	hc := *c.httpClient()
+	hc.Timeout = 0
+
+	resp, err := c.do(&hc, req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	response := newResponse(resp)
+
+	err = CheckResponse(resp)
+	if err != nil {
+		c.onError(req, err)
+		return response, err
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return response, timeoutError(err)
+}
+
 func newResponse(r *http.Response) *Response {
 	response := &Response{Response: r}
 	return response
 }
 
 func (c *Client) Do(req *http.Request, v interface{}) (*Response, error) {
-	resp, err := c.client.Do(req)
+	// the page keeps the request without the deadline to fetch the next one
+	page := req
+	req, cancel := c.withDeadline(req)
+	defer cancel()
+
+	resp, err := c.do(c.httpClient(), req)
 	if err != nil {
 		return nil, err
 	}
@@ -199,6 +480,7 @@ func (c *Client) Do(req *http.Request, v interface{}) (*Response, error) {
 
 	err = CheckResponse(resp)
 	if err != nil {
+		c.onError(req, err)
 		return response, err
 	}
 
@@ -208,6 +490,12 @@ func (c *Client) Do(req *http.Request, v interface{}) (*Response, error) {
 		} else {
 			err = json.NewDecoder(resp.Body).Decode(v)
 		}
+		if err != nil {
+			err = timeoutError(err)
+			c.onError(req, err)
+		} else {
+			c.setPage(response, page, v)
+		}
 	}
 	return response, err
 }
@@ -215,17 +503,57 @@ func (c *Client) Do(req *http.Request, v interface{}) (*Response, error) {
 // Pulp Api docs:
 // http://pulp.readthedocs.org/en/latest/dev-guide/conventions/exceptions.html#exception-handling
 type ErrorResponse struct {
-	Response     *http.Response // HTTP response that caused this error
-	ResourceID   string         `json:"resource_id"`
-	Message      string         `json:"error_message"` // error message
-	ErrorDetails *Error         `json:"error"`         // more detail on individual errors
-
+	Response          *http.Response         // HTTP response that caused this error
+	HttpStatus        int                    `json:"http_status"`
+	HttpRequestMethod string                 `json:"http_request_method"`
+	Message           string                 `json:"error_message"` // error message
+	Href              string                 `json:"_href"`
+	Resources         map[string]interface{} `json:"resources"` // missing or conflicting resources
+	Exception         []string               `json:"exception"`
+	Traceback         []string               `json:"traceback"`
+	ErrorDetails      *Error                 `json:"error"` // more detail on individual errors
 }
 
 func (r *ErrorResponse) Error() string {
 	path, _ := url.QueryUnescape(r.Response.Request.URL.Opaque)
+	if path == "" {
+		path = r.Response.Request.URL.Path
+	}
 	ru := fmt.Sprintf("%s://%s%s", r.Response.Request.URL.Scheme, r.Response.Request.URL.Host, path)
-	return fmt.Sprintf("%v %s: %d %v", r.Response.Request.Method, ru, r.Response.StatusCode, r.Message)
+
+	msg := r.Message
+	if msg == "" && r.ErrorDetails != nil {
+		msg = r.ErrorDetails.Description
+	}
+	return fmt.Sprintf("%v %s: %d %v", r.Response.Request.Method, ru, r.Response.StatusCode, msg)
+}
+
+// Code returns the pulp error code, like PLP0009, if any.
+func (r *ErrorResponse) Code() string {
+	if r.ErrorDetails == nil {
+		return ""
+	}
+	return r.ErrorDetails.Code
+}
+
+func (r *ErrorResponse) IsBadRequest() bool {
+	return r.Response.StatusCode == http.StatusBadRequest
+}
+
+func (r *ErrorResponse) IsUnauthorized() bool {
+	return r.Response.StatusCode == http.StatusUnauthorized
+}
+
+func (r *ErrorResponse) IsForbidden() bool {
+	return r.Response.StatusCode == http.StatusForbidden
+}
+
+func (r *ErrorResponse) IsNotFound() bool {
+	return r.Response.StatusCode == http.StatusNotFound
+}
+
+func (r *ErrorResponse) IsConflict() bool {
+	return r.Response.StatusCode == http.StatusConflict
 }
 
 // Pulp Api docs:
@@ -237,17 +565,6 @@ type Error struct {
 	Sub_errors  json.RawMessage `json:"sub_errors"`
 }
 
-// Pulp Api docs:
-// http://pulp.readthedocs.org/en/latest/dev-guide/conventions/sync-v-async.html#call-report
-type CallReport struct {
-	Result       string `json:"result"`
-	Error        *Error `json:"error"`
-	SpawnedTasks []struct {
-		Href   string `json:"_href"`
-		TaskId string `json:"task_id"`
-	} `json:"spawned_tasks"`
-}
-
 func (e *Error) Error() string {
 	return fmt.Sprintf("%v error: %v",
 		e.Code, e.Description)
@@ -0,0 +1,62 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+const PuppetForgeFeed = "https://forge.puppetlabs.com"
+
+const PuppetInstallDistributorType = "puppet_install_distributor"
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/plugins/pulp_puppet/tech-reference/plugin_conf.html#install-distributor
+type PuppetInstallDistributorConfig struct {
+	InstallPath string `json:"install_path"`
+	SubdirName  string `json:"subdir_name,omitempty"`
+}
+
+// ListPuppetModules returns the puppet modules of the repository.
+func (s *UnitsService) ListPuppetModules(repository string) ([]*PuppetModuleUnit, *Response, error) {
+	if err := s.client.requireContentType(PuppetModuleUnitType, "pulp_puppet"); err != nil {
+		return nil, nil, err
+	}
+
+	units, resp, err := s.SearchUnits(repository, NewUnitCriteria(PuppetModuleUnitType))
+	if err != nil {
+		return nil, resp, err
+	}
+
+	var modules []*PuppetModuleUnit
+	for _, u := range units {
+		if m := u.PuppetModule(); m != nil {
+			modules = append(modules, m)
+		}
+	}
+	return modules, resp, err
+}
+
+// SyncPuppetForge syncs the puppet repository from Puppet Forge, limited to
+// the modules matching the queries, e.g. "apache" or "puppetlabs/stdlib".
+// The configured feed and queries of the importer are left untouched.
+func (s *RepositoriesService) SyncPuppetForge(repository string, queries ...string) (*CallReport, *Response, error) {
+	if err := s.client.requireContentType(PuppetModuleUnitType, "pulp_puppet"); err != nil {
+		return nil, nil, err
+	}
+
+	return s.SyncRepositoryWithConfig(repository, &PuppetImporterConfig{
+		Feed:    PuppetForgeFeed,
+		Queries: queries,
+	})
+}
@@ -0,0 +1,123 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"strings"
+)
+
+const (
+	PythonPackageUnitType = "python_package"
+	PythonDistributorType = "python_distributor"
+	PyPIFeed              = "https://pypi.python.org/"
+)
+
+func init() {
+	RegisterUnitType(PythonPackageUnitType, func() interface{} { return new(PythonPackageUnit) })
+}
+
+// PackageNames is a comma separated list of the packages to sync.
+//
+// Pulp Api docs:
+// http://pulp-python.readthedocs.io/en/latest/tech-reference/importer.html
+type PythonImporterConfig struct {
+	Feed          string `json:"feed,omitempty"`
+	PackageNames  string `json:"package_names,omitempty"`
+	RemoveMissing *bool  `json:"remove_missing,omitempty"`
+	SslCaCert     string `json:"ssl_ca_cert,omitempty"`
+	SslClientCert string `json:"ssl_client_cert,omitempty"`
+	SslClientKey  string `json:"ssl_client_key,omitempty"`
+	SslValidation *bool  `json:"ssl_validation,omitempty"`
+	ProxyHost     string `json:"proxy_host,omitempty"`
+	ProxyPort     int    `json:"proxy_port,omitempty"`
+	ProxyUsername string `json:"proxy_username,omitempty"`
+	ProxyPassword string `json:"proxy_password,omitempty"`
+}
+
+func (c PythonImporterConfig) String() string {
+	return Stringify(c)
+}
+
+// The python distributor has no required config, it publishes the
+// repository at /pulp/python/web/<repo id>/.
+//
+// Pulp Api docs:
+// http://pulp-python.readthedocs.io/en/latest/tech-reference/distributor.html
+type PythonDistributorConfig struct{}
+
+// Pulp Api docs:
+// http://pulp-python.readthedocs.io/en/latest/tech-reference/python_package.html
+type PythonPackageUnit struct {
+	UnitMetadata
+	Name         string `json:"name"`
+	Version      string `json:"version"`
+	Filename     string `json:"filename"`
+	PackageType  string `json:"packagetype"`
+	Checksum     string `json:"checksum"`
+	ChecksumType string `json:"checksum_type"`
+	Summary      string `json:"summary"`
+	Description  string `json:"description"`
+	Author       string `json:"author"`
+	AuthorEmail  string `json:"author_email"`
+	HomePage     string `json:"home_page"`
+	License      string `json:"license"`
+	Platform     string `json:"platform"`
+}
+
+func (u *Unit) PythonPackage() *PythonPackageUnit {
+	m, _ := u.Metadata.(*PythonPackageUnit)
+	return m
+}
+
+// PythonContentPath returns the path of a published python repository,
+// holding the simple index at simple/.
+func PythonContentPath(repository string) string {
+	return "/pulp/python/web/" + strings.Trim(repository, "/") + "/"
+}
+
+// ListPythonPackages returns the python packages of the repository.
+func (s *UnitsService) ListPythonPackages(repository string) ([]*PythonPackageUnit, *Response, error) {
+	if err := s.client.requireContentType(PythonPackageUnitType, "pulp_python"); err != nil {
+		return nil, nil, err
+	}
+
+	units, resp, err := s.SearchUnits(repository, NewUnitCriteria(PythonPackageUnitType))
+	if err != nil {
+		return nil, resp, err
+	}
+
+	var packages []*PythonPackageUnit
+	for _, u := range units {
+		if p := u.PythonPackage(); p != nil {
+			packages = append(packages, p)
+		}
+	}
+	return packages, resp, err
+}
+
+// SyncPythonPackages syncs the python repository, limited to the given
+// packages, e.g. "requests". The configured packages of the importer are
+// left untouched.
+func (s *RepositoriesService) SyncPythonPackages(repository string, names ...string) (*CallReport, *Response, error) {
+	if err := s.client.requireContentType(PythonPackageUnitType, "pulp_python"); err != nil {
+		return nil, nil, err
+	}
+
+	return s.SyncRepositoryWithConfig(repository, &PythonImporterConfig{
+		PackageNames: strings.Join(names, ","),
+	})
+}
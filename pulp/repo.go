@@ -0,0 +1,137 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"sync"
+)
+
+// Repo is a repository bound to the client, for scripts which work on a
+// repository at a time:
+//
+//	repo, err := client.Repositories.Repo("my-repo")
+//	cr, err := repo.Sync()
+//	...
+//	units, err := repo.Units(pulp.NewUnitCriteria(pulp.RpmUnitType))
+//
+// The importers and distributors are fetched on first use and cached until
+// Refresh.
+type Repo struct {
+	*Repository
+
+	client *Client
+
+	mu           sync.Mutex
+	importers    []*Importer
+	distributors []*Distributor
+}
+
+// Repo fetches the repository.
+func (s *RepositoriesService) Repo(repository string) (*Repo, error) {
+	r, _, err := s.GetRepository(repository, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Repo{Repository: r, client: s.client}, nil
+}
+
+// Refresh fetches the repository again and drops the cached importers and
+// distributors.
+func (r *Repo) Refresh() error {
+	repo, _, err := r.client.Repositories.GetRepository(r.Id, nil)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Repository = repo
+	r.importers = nil
+	r.distributors = nil
+	return nil
+}
+
+func (r *Repo) Importers() ([]*Importer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.importers == nil {
+		i, _, err := r.client.Repositories.ListImporters(r.Id)
+		if err != nil {
+			return nil, err
+		}
+		r.importers = i
+	}
+	return r.importers, nil
+}
+
+func (r *Repo) Distributors() ([]*Distributor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.distributors == nil {
+		d, _, err := r.client.Repositories.ListDistributors(r.Id)
+		if err != nil {
+			return nil, err
+		}
+		r.distributors = d
+	}
+	return r.distributors, nil
+}
+
+func (r *Repo) Sync() (*CallReport, error) {
+	cr, _, err := r.client.Repositories.SyncRepository(r.Id)
+	return cr, err
+}
+
+// Publish publishes the repository with the distributor.
+func (r *Repo) Publish(distributor string) (*CallReport, error) {
+	cr, _, err := r.client.Repositories.PublishRepository(r.Id, &PublishOptions{Id: distributor})
+	return cr, err
+}
+
+// PublishAll publishes the repository with each of its distributors and
+// waits for the publish tasks.
+func (r *Repo) PublishAll(poll *PollOptions) ([]*Task, error) {
+	return r.client.Repositories.publishAndWait(r.Id, nil, poll)
+}
+
+// Units returns the units of the repository matching the criteria, all if
+// nil.
+func (r *Repo) Units(criteria *UnitCriteria) ([]*Unit, error) {
+	if criteria == nil {
+		criteria = NewUnitCriteria()
+	}
+	u, _, err := r.client.Units.SearchUnits(r.Id, criteria)
+	return u, err
+}
+
+// UnitIterator iterates over the units of the repository matching the
+// criteria a page at a time.
+func (r *Repo) UnitIterator(criteria *UnitCriteria) *UnitIterator {
+	return r.client.Units.NewUnitIterator(r.Id, criteria, 0)
+}
+
+func (r *Repo) SyncHistory(opt *HistoryOptions) ([]*SyncResult, error) {
+	h, _, err := r.client.Repositories.GetSyncHistory(r.Id, opt)
+	return h, err
+}
+
+func (r *Repo) Delete() (*CallReport, error) {
+	cr, _, err := r.client.Repositories.DeleteRepository(r.Id)
+	return cr, err
+}
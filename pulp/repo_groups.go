@@ -0,0 +1,261 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"fmt"
+)
+
+type RepoGroupsService struct {
+	client *Client
+}
+
+type RepoGroup struct {
+	Id          string            `json:"id"`
+	DisplayName string            `json:"display_name"`
+	Description string            `json:"description"`
+	RepoIds     []string          `json:"repo_ids"`
+	Notes       map[string]string `json:"notes"`
+	Href        string            `json:"_href"`
+}
+
+func (g RepoGroup) String() string {
+	return Stringify(g)
+}
+
+type GroupDistributor struct {
+	Id                string                 `json:"id"`
+	DistributorTypeId string                 `json:"distributor_type_id"`
+	RepoGroupId       string                 `json:"repo_group_id"`
+	Config            map[string]interface{} `json:"config"`
+	LastPublish       PulpTime               `json:"last_publish"`
+	Href              string                 `json:"_href"`
+}
+
+func (d GroupDistributor) String() string {
+	return Stringify(d)
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/repo/groups/retrieval.html
+func (s *RepoGroupsService) ListRepoGroups() ([]*RepoGroup, *Response, error) {
+	req, err := s.client.NewRequest("GET", "repo_groups/", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var g []*RepoGroup
+	resp, err := s.client.Do(req, &g)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return g, resp, err
+}
+
+func (s *RepoGroupsService) GetRepoGroup(group string) (*RepoGroup, *Response, error) {
+	u := fmt.Sprintf("repo_groups/%s/", group)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g := new(RepoGroup)
+	resp, err := s.client.Do(req, g)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return g, resp, err
+}
+
+type GroupDistributorOptions struct {
+	DistributorId     string      `json:"distributor_id,omitempty"`
+	DistributorTypeId string      `json:"distributor_type_id"`
+	DistributorConfig interface{} `json:"distributor_config"`
+}
+
+type CreateRepoGroupOptions struct {
+	Id           string                     `json:"id"`
+	DisplayName  string                     `json:"display_name,omitempty"`
+	Description  string                     `json:"description,omitempty"`
+	RepoIds      []string                   `json:"repo_ids,omitempty"`
+	Notes        map[string]string          `json:"notes,omitempty"`
+	Distributors []*GroupDistributorOptions `json:"distributors,omitempty"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/repo/groups/cud.html
+func (s *RepoGroupsService) CreateRepoGroup(opt *CreateRepoGroupOptions) (*RepoGroup, *Response, error) {
+	req, err := s.client.NewRequest("POST", "repo_groups/", opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g := new(RepoGroup)
+	resp, err := s.client.Do(req, g)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return g, resp, err
+}
+
+// only the non nil fields are updated, a nil note value removes the note
+type UpdateRepoGroupOptions struct {
+	DisplayName *string            `json:"display_name,omitempty"`
+	Description *string            `json:"description,omitempty"`
+	Notes       map[string]*string `json:"notes,omitempty"`
+}
+
+func (s *RepoGroupsService) UpdateRepoGroup(group string, opt *UpdateRepoGroupOptions) (*RepoGroup, *Response, error) {
+	u := fmt.Sprintf("repo_groups/%s/", group)
+
+	req, err := s.client.NewRequest("PUT", u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g := new(RepoGroup)
+	resp, err := s.client.Do(req, g)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return g, resp, err
+}
+
+func (s *RepoGroupsService) DeleteRepoGroup(group string) (*Response, error) {
+	u := fmt.Sprintf("repo_groups/%s/", group)
+
+	req, err := s.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
+
+// AssociateRepositories adds the repositories to the group and returns the
+// ids of all the group members.
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/repo/groups/membership.html
+func (s *RepoGroupsService) AssociateRepositories(group string, repositories []string) ([]string, *Response, error) {
+	return s.membership(group, "associate", repositories)
+}
+
+// UnassociateRepositories removes the repositories from the group and
+// returns the ids of the remaining group members.
+func (s *RepoGroupsService) UnassociateRepositories(group string, repositories []string) ([]string, *Response, error) {
+	return s.membership(group, "unassociate", repositories)
+}
+
+func (s *RepoGroupsService) membership(group string, action string, repositories []string) ([]string, *Response, error) {
+	u := fmt.Sprintf("repo_groups/%s/actions/%s/", group, action)
+
+	opt := &searchRequest{Criteria: NewCriteria().Where(In("id", Strings(repositories)...))}
+
+	req, err := s.client.NewRequest("POST", u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ids []string
+	resp, err := s.client.Do(req, &ids)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return ids, resp, err
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/repo/groups/distributors.html
+func (s *RepoGroupsService) ListGroupDistributors(group string) ([]*GroupDistributor, *Response, error) {
+	u := fmt.Sprintf("repo_groups/%s/distributors/", group)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var d []*GroupDistributor
+	resp, err := s.client.Do(req, &d)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return d, resp, err
+}
+
+func (s *RepoGroupsService) AddGroupDistributor(group string, opt *GroupDistributorOptions) (*GroupDistributor, *Response, error) {
+	u := fmt.Sprintf("repo_groups/%s/distributors/", group)
+
+	req, err := s.client.NewRequest("POST", u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d := new(GroupDistributor)
+	resp, err := s.client.Do(req, d)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return d, resp, err
+}
+
+func (s *RepoGroupsService) RemoveGroupDistributor(group string, distributor string) (*Response, error) {
+	u := fmt.Sprintf("repo_groups/%s/distributors/%s/", group, distributor)
+
+	req, err := s.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
+
+type PublishOptions struct {
+	Id             string      `json:"id"`
+	OverrideConfig interface{} `json:"override_config,omitempty"`
+
+	// return the publish tasks of the distributor already waiting or
+	// running instead of starting a new publish, repositories only
+	ReuseRunning bool `json:"-"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/repo/groups/publish.html
+func (s *RepoGroupsService) PublishRepoGroup(group string, opt *PublishOptions) (*CallReport, *Response, error) {
+	u := fmt.Sprintf("repo_groups/%s/actions/publish/", group)
+
+	req, err := s.client.NewRequest("POST", u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cr := new(CallReport)
+	resp, err := s.client.Do(req, cr)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return cr, resp, err
+}
@@ -0,0 +1,286 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// Repomd is the index of the metadata of a published yum repository,
+// repodata/repomd.xml.
+type Repomd struct {
+	Revision string        `xml:"revision"`
+	Data     []*RepomdData `xml:"data"`
+}
+
+type RepomdData struct {
+	Type         string
+	Checksum     string
+	ChecksumType string
+	Location     string
+	Timestamp    int64
+	Size         int64
+	OpenSize     int64
+}
+
+// xml elements with attributes of the repo metadata
+type (
+	xmlChecksum struct {
+		Type  string `xml:"type,attr"`
+		Value string `xml:",chardata"`
+	}
+	xmlLocation struct {
+		Href string `xml:"href,attr"`
+	}
+)
+
+func (d *RepomdData) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	var x struct {
+		Type      string      `xml:"type,attr"`
+		Checksum  xmlChecksum `xml:"checksum"`
+		Location  xmlLocation `xml:"location"`
+		Timestamp float64     `xml:"timestamp"`
+		Size      int64       `xml:"size"`
+		OpenSize  int64       `xml:"open-size"`
+	}
+	if err := dec.DecodeElement(&x, &start); err != nil {
+		return err
+	}
+
+	*d = RepomdData{
+		Type:         x.Type,
+		Checksum:     x.Checksum.Value,
+		ChecksumType: x.Checksum.Type,
+		Location:     x.Location.Href,
+		Timestamp:    int64(x.Timestamp),
+		Size:         x.Size,
+		OpenSize:     x.OpenSize,
+	}
+	return nil
+}
+
+// Find returns the metadata of the type, like primary, or nil.
+func (r *Repomd) Find(dataType string) *RepomdData {
+	for _, d := range r.Data {
+		if d.Type == dataType {
+			return d
+		}
+	}
+	return nil
+}
+
+// Primary is the package list of a published yum repository.
+type Primary struct {
+	PackageCount int               `xml:"packages,attr"`
+	Packages     []*PrimaryPackage `xml:"package"`
+}
+
+type PrimaryPackage struct {
+	Type         string
+	Name         string
+	Arch         string
+	Epoch        string
+	Version      string
+	Release      string
+	Checksum     string
+	ChecksumType string
+	Location     string
+}
+
+func (p *PrimaryPackage) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	var x struct {
+		Type    string `xml:"type,attr"`
+		Name    string `xml:"name"`
+		Arch    string `xml:"arch"`
+		Version struct {
+			Epoch   string `xml:"epoch,attr"`
+			Version string `xml:"ver,attr"`
+			Release string `xml:"rel,attr"`
+		} `xml:"version"`
+		Checksum xmlChecksum `xml:"checksum"`
+		Location xmlLocation `xml:"location"`
+	}
+	if err := dec.DecodeElement(&x, &start); err != nil {
+		return err
+	}
+
+	*p = PrimaryPackage{
+		Type:         x.Type,
+		Name:         x.Name,
+		Arch:         x.Arch,
+		Epoch:        x.Version.Epoch,
+		Version:      x.Version.Version,
+		Release:      x.Version.Release,
+		Checksum:     x.Checksum.Value,
+		ChecksumType: x.Checksum.Type,
+		Location:     x.Location.Href,
+	}
+	return nil
+}
+
+func (p *PrimaryPackage) NEVRA() *NEVRA {
+	return &NEVRA{
+		Name:    p.Name,
+		Epoch:   p.Epoch,
+		Version: p.Version,
+		Release: p.Release,
+		Arch:    p.Arch,
+	}
+}
+
+// GetRepomd fetches and parses the repomd.xml of the published yum
+// repository.
+func (c *Client) GetRepomd(repository string) (*Repomd, error) {
+	if err := c.requireContentType(RpmUnitType, "pulp_rpm"); err != nil {
+		return nil, err
+	}
+
+	base, err := c.PublishedRepoPath(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.fetchContent(base + "repodata/repomd.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(Repomd)
+	if err := xml.Unmarshal(data, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetPrimary fetches and parses the primary metadata listed in the
+// repomd of the published yum repository, verifying its checksum.
+func (c *Client) GetPrimary(repository string, repomd *Repomd) (*Primary, error) {
+	d := repomd.Find("primary")
+	if d == nil {
+		return nil, fmt.Errorf("pulp: no primary metadata published for repository %s", repository)
+	}
+
+	base, err := c.PublishedRepoPath(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.fetchContent(base + d.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	sums, err := NewChecksumReader(bytes.NewReader(data), d.ChecksumType)
+	if err != nil {
+		return nil, err
+	}
+	io.Copy(ioutil.Discard, sums)
+	if err := sums.Verify(d.ChecksumType, d.Checksum); err != nil {
+		return nil, err
+	}
+
+	var r io.Reader = bytes.NewReader(data)
+	if strings.HasSuffix(d.Location, ".gz") {
+		if r, err = gzip.NewReader(r); err != nil {
+			return nil, err
+		}
+	} else if !strings.HasSuffix(d.Location, ".xml") {
+		return nil, fmt.Errorf("pulp: unsupported compression of %s", d.Location)
+	}
+
+	p := new(Primary)
+	if err := xml.NewDecoder(r).Decode(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// PublishCheck compares the published metadata of a yum repository with
+// its units.
+type PublishCheck struct {
+	Repomd *Repomd
+
+	// packages listed in the primary metadata
+	PublishedPackages int
+
+	// rpm and srpm units of the repository
+	RepoPackages int
+
+	// signature of repomd.xml, not verified
+	Signature *RepodataSignature
+}
+
+func (c PublishCheck) String() string {
+	return Stringify(c)
+}
+
+// Consistent reports if the published metadata lists all the packages of
+// the repository.
+func (c *PublishCheck) Consistent() bool {
+	return c.PublishedPackages == c.RepoPackages
+}
+
+// CheckPublishedRepo fetches the published metadata of the yum repository
+// and counts its packages and the rpm and srpm units of the repository, to
+// verify that a publish produced consistent metadata.
+func (c *Client) CheckPublishedRepo(repository string) (*PublishCheck, error) {
+	repomd, err := c.GetRepomd(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	primary, err := c.GetPrimary(repository, repomd)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &PublishCheck{Repomd: repomd, PublishedPackages: len(primary.Packages)}
+
+	check.Signature, err = c.CheckRepodataSignature(repository, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	criteria := NewUnitCriteria(RpmUnitType, SrpmUnitType).SelectUnitFields("_id")
+	_, err = c.Units.StreamUnits(repository, criteria, func(*Unit) error {
+		check.RepoPackages++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+func (c *Client) fetchContent(contentUrl string) ([]byte, error) {
+	req, err := c.NewContentRequest("GET", contentUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if _, err := c.Download(req, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
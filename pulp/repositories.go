@@ -17,7 +17,10 @@
 package pulp
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 )
 
 type RepositoriesService struct {
@@ -25,16 +28,95 @@ type RepositoriesService struct {
 }
 
 type Repository struct {
-	Id        string      `json:"id"`
-	Name      string      `json:"display_name"`
-	Importers []*Importer `json:"importers"`
+	Id              string            `json:"id"`
+	Name            string            `json:"display_name"`
+	Description     string            `json:"description"`
+	Notes           map[string]string `json:"notes"`
+	LastUnitAdded   PulpTime          `json:"last_unit_added"`
+	LastUnitRemoved PulpTime          `json:"last_unit_removed"`
+	Importers       []*Importer       `json:"importers"`
+	Distributors    []*Distributor    `json:"distributors"`
+
+	// number of units associated with the repository by unit type id
+	ContentUnitCounts map[string]int `json:"content_unit_counts"`
+
+	// the fields unknown to the client, encoded again with the repository
+	ExtraFields map[string]json.RawMessage `json:"-"`
 }
 
 func (r Repository) String() string {
 	return Stringify(r)
 }
 
-func (s *RepositoriesService) ListRepositories(opt *GetRepositoryOptions) ([]*Repository, *Response, error) {
+func (r *Repository) UnmarshalJSON(data []byte) error {
+	type repository Repository
+	if err := json.Unmarshal(data, (*repository)(r)); err != nil {
+		return err
+	}
+
+	var err error
+	r.ExtraFields, err = extraFields(data, r)
+	return err
+}
+
+func (r Repository) MarshalJSON() ([]byte, error) {
+	type repository Repository
+	return marshalWithExtra(repository(r), r.ExtraFields)
+}
+
+// UnitCount returns the number of units of the type in the repository.
+func (r *Repository) UnitCount(unitType string) int {
+	return r.ContentUnitCounts[unitType]
+}
+
+func (r *Repository) RPMCount() int {
+	return r.UnitCount(RpmUnitType)
+}
+
+func (r *Repository) ErrataCount() int {
+	return r.UnitCount(ErratumUnitType)
+}
+
+// TotalUnits returns the number of units of all types in the repository.
+func (r *Repository) TotalUnits() int {
+	total := 0
+	for _, n := range r.ContentUnitCounts {
+		total += n
+	}
+	return total
+}
+
+// Importer returns the importer of the repository, nil if the repository
+// has none or was fetched without its details.
+func (r *Repository) Importer() *Importer {
+	if len(r.Importers) == 0 {
+		return nil
+	}
+	return r.Importers[0]
+}
+
+// Distributor returns the distributor of the repository with the given id,
+// nil if there is none or the repository was fetched without its details.
+func (r *Repository) Distributor(id string) *Distributor {
+	for _, d := range r.Distributors {
+		if d.Id == id {
+			return d
+		}
+	}
+	return nil
+}
+
+// Details includes both the importers and the distributors of each
+// repository.
+type ListRepositoriesOptions struct {
+	Details      bool `url:"details,omitempty" json:"details,omitempty"`
+	Importers    bool `url:"importers,omitempty" json:"importers,omitempty"`
+	Distributors bool `url:"distributors,omitempty" json:"distributors,omitempty"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/repo/retrieval.html#retrieve-all-repositories
+func (s *RepositoriesService) ListRepositories(opt *ListRepositoriesOptions) ([]*Repository, *Response, error) {
 
 	req, err := s.client.NewRequest("GET", "repositories/", opt)
 	if err != nil {
@@ -74,19 +156,320 @@ func (s *RepositoriesService) GetRepository(
 }
 
 func (s *RepositoriesService) SyncRepository(repository string) (*CallReport, *Response, error) {
+	return s.SyncRepositoryWithConfig(repository, nil)
+}
+
+type syncRequest struct {
+	OverrideConfig interface{} `json:"override_config"`
+}
+
+// SyncRepositoryWithConfig syncs the repository with the importer config
+// overridden for this sync only, e.g. a *YumImporterConfig.
+func (s *RepositoriesService) SyncRepositoryWithConfig(repository string, overrideConfig interface{}) (*CallReport, *Response, error) {
+	return s.SyncRepositoryWithOptions(repository, &SyncOptions{OverrideConfig: overrideConfig})
+}
+
+type SyncOptions struct {
+	// importer config overridden for this sync only
+	OverrideConfig interface{}
+
+	// return the sync tasks of the repository already waiting or running
+	// instead of starting a new sync
+	ReuseRunning bool
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/repo/sync.html#sync-a-repository
+func (s *RepositoriesService) SyncRepositoryWithOptions(repository string, opt *SyncOptions) (*CallReport, *Response, error) {
 	u := fmt.Sprintf("repositories/%s/actions/sync/", repository)
 
-	req, err := s.client.NewRequest("POST", u, nil)
+	if opt == nil {
+		opt = &SyncOptions{}
+	}
+	tags := []string{RepositoryTag(repository), ActionTag("sync")}
+
+	if opt.ReuseRunning {
+		cr, resp, err := s.client.Tasks.pendingTasks(tags)
+		if err != nil || cr != nil {
+			return cr, resp, err
+		}
+	}
+
+	var body interface{}
+	if opt.OverrideConfig != nil {
+		body = &syncRequest{OverrideConfig: opt.OverrideConfig}
+	}
+
+	req, err := s.client.NewRequest("POST", u, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cr := new(CallReport)
+	resp, err := s.client.Do(req, cr)
+
+	if err != nil {
+		if opt.ReuseRunning && errors.Is(err, ErrConflict) {
+			if running, _, serr := s.client.Tasks.pendingTasks(tags); serr == nil && running != nil {
+				return running, resp, nil
+			}
+		}
+		return nil, resp, err
+	}
+
+	return cr, resp, err
+}
+
+type CreateRepositoryOptions struct {
+	Id             string                   `json:"id"`
+	DisplayName    string                   `json:"display_name,omitempty"`
+	Description    string                   `json:"description,omitempty"`
+	Notes          map[string]string        `json:"notes,omitempty"`
+	ImporterTypeId string                   `json:"importer_type_id,omitempty"`
+	ImporterConfig interface{}              `json:"importer_config,omitempty"`
+	Distributors   []*AddDistributorOptions `json:"distributors,omitempty"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/repo/cud.html#create-a-repository
+func (s *RepositoriesService) CreateRepository(opt *CreateRepositoryOptions) (*Repository, *Response, error) {
+	req, err := s.client.NewRequest("POST", "repositories/", opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := new(Repository)
+	resp, err := s.client.Do(req, r)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return r, resp, err
+}
+
+// only the non nil fields are updated, a nil note value removes the note
+type UpdateRepositoryOptions struct {
+	DisplayName *string                `json:"display_name,omitempty"`
+	Description *string                `json:"description,omitempty"`
+	Notes       map[string]interface{} `json:"notes,omitempty"`
+}
+
+type updateRepositoryRequest struct {
+	Delta *UpdateRepositoryOptions `json:"delta"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/repo/cud.html#update-a-repository
+func (s *RepositoriesService) UpdateRepository(repository string, opt *UpdateRepositoryOptions) (*CallReport, *Response, error) {
+	u := fmt.Sprintf("repositories/%s/", repository)
+
+	req, err := s.client.NewRequest("PUT", u, &updateRepositoryRequest{Delta: opt})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cr := new(CallReport)
+	resp, err := s.client.Do(req, cr)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return cr, resp, err
+}
+
+// the repository is deleted by a spawned task
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/repo/cud.html#delete-a-repository
+func (s *RepositoriesService) DeleteRepository(repository string) (*CallReport, *Response, error) {
+	u := fmt.Sprintf("repositories/%s/", repository)
+
+	req, err := s.client.NewRequest("DELETE", u, nil)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	cr := new(CallReport)
-	resp, err := s.client.Do(req, &cr)
+	resp, err := s.client.Do(req, cr)
+	if err != nil {
+		return nil, resp, err
+	}
 
+	return cr, resp, err
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/repo/publish.html
+func (s *RepositoriesService) PublishRepository(repository string, opt *PublishOptions) (*CallReport, *Response, error) {
+	u := fmt.Sprintf("repositories/%s/actions/publish/", repository)
+
+	var tags []string
+	if opt != nil && opt.ReuseRunning {
+		tags = []string{RepositoryTag(repository), DistributorTag(opt.Id), ActionTag("publish")}
+
+		cr, resp, err := s.client.Tasks.pendingTasks(tags)
+		if err != nil || cr != nil {
+			return cr, resp, err
+		}
+	}
+
+	req, err := s.client.NewRequest("POST", u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cr := new(CallReport)
+	resp, err := s.client.Do(req, cr)
 	if err != nil {
+		if tags != nil && errors.Is(err, ErrConflict) {
+			if running, _, serr := s.client.Tasks.pendingTasks(tags); serr == nil && running != nil {
+				return running, resp, nil
+			}
+		}
 		return nil, resp, err
 	}
 
 	return cr, resp, err
 }
+
+// publishAndWait publishes the repository with each of its distributors
+// picked by the selector, all if nil, one after the other, and waits for
+// the publish tasks.
+func (s *RepositoriesService) publishAndWait(repository string, selector DistributorSelector, poll *PollOptions) ([]*Task, error) {
+	distributors, _, err := s.ListDistributors(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []*Task
+	for _, d := range distributors {
+		if selector != nil && !selector(d) {
+			continue
+		}
+		cr, _, err := s.PublishRepository(repository, &PublishOptions{Id: d.Id})
+		if err != nil {
+			return tasks, err
+		}
+		t, err := cr.WaitAll(s.client, poll)
+		tasks = append(tasks, t...)
+		if err != nil {
+			return tasks, err
+		}
+	}
+	return tasks, nil
+}
+
+// Criteria filters apply to the repository fields, e.g.
+// Eq("notes._repo-type", "rpm-repo") or Regex("display_name", "^prod-").
+// ImporterTypeId filters the found repositories on their importer type;
+// this is done by the client on each page of results. Details includes
+// both the importers and the distributors of each repository.
+type SearchRepositoriesOptions struct {
+	Criteria       *Criteria
+	Details        bool
+	Importers      bool
+	Distributors   bool
+	ImporterTypeId string
+}
+
+type repositorySearchRequest struct {
+	Criteria     *Criteria `json:"criteria"`
+	Details      bool      `json:"details,omitempty"`
+	Importers    bool      `json:"importers,omitempty"`
+	Distributors bool      `json:"distributors,omitempty"`
+}
+
+// NoteFilter matches repositories having the note set to value.
+func NoteFilter(note string, value string) Filter {
+	return Eq("notes."+note, value)
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/repo/retrieval.html#advanced-search-for-repositories
+func (s *RepositoriesService) SearchRepositories(opt *SearchRepositoriesOptions) ([]*Repository, *Response, error) {
+	if opt == nil {
+		opt = &SearchRepositoriesOptions{}
+	}
+
+	flags := &ListRepositoriesOptions{
+		Details:      opt.Details,
+		Importers:    opt.Importers || opt.ImporterTypeId != "",
+		Distributors: opt.Distributors,
+	}
+
+	r := &repositorySearchRequest{
+		Criteria:     opt.Criteria,
+		Details:      flags.Details,
+		Importers:    flags.Importers,
+		Distributors: flags.Distributors,
+	}
+	if r.Criteria == nil {
+		r.Criteria = NewCriteria()
+	}
+
+	// the flags are also sent as query parameters, like for
+	// ListRepositories
+	req, err := s.client.NewRequestWithQuery("POST", "repositories/search/", flags, r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var repos []*Repository
+	resp, err := s.client.Do(req, &repos)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	if opt.ImporterTypeId != "" {
+		repos = filterByImporterType(repos, opt.ImporterTypeId)
+	}
+
+	return repos, resp, err
+}
+
+// FindReposContainingUnit returns the repositories containing a unit of the
+// content type matching the filters, e.g. the repositories containing a
+// version of an rpm:
+//
+//	repos, _, err := client.Repositories.FindReposContainingUnit(pulp.RpmUnitType,
+//		pulp.Eq("name", "openssl"), pulp.Eq("version", "1.0.1e"))
+func (s *RepositoriesService) FindReposContainingUnit(typeId string, filters ...Filter) ([]*Repository, *Response, error) {
+	criteria := NewCriteria().Where(filters...).Select("_id")
+
+	units, resp, err := s.client.Content.SearchUnits(typeId, criteria)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	seen := make(map[string]bool)
+	var ids []string
+	for _, u := range units {
+		for _, id := range u.RepositoryMemberships {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil, resp, nil
+	}
+	sort.Strings(ids)
+
+	return s.SearchRepositories(&SearchRepositoriesOptions{
+		Criteria: NewCriteria().Where(In("id", Strings(ids)...)).OrderBy("id", SortAscending),
+	})
+}
+
+func filterByImporterType(repos []*Repository, importerType string) []*Repository {
+	var filtered []*Repository
+	for _, r := range repos {
+		for _, i := range r.Importers {
+			if i.ImporterTypeId == importerType {
+				filtered = append(filtered, r)
+				break
+			}
+		}
+	}
+	return filtered
+}
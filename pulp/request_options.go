@@ -0,0 +1,108 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// RequestOptions apply to all the requests of a client returned by
+// WithOptions.
+type RequestOptions struct {
+	// the requests and the polling of tasks are canceled when the context
+	// is done
+	Context context.Context
+
+	// replaces the timeout of the client, see WithTimeout; zero keeps it,
+	// a negative timeout disables it
+	Timeout time.Duration
+}
+
+// WithOptions returns a client sending its requests with the options, e.g.
+//
+//	client.WithOptions(&pulp.RequestOptions{Timeout: time.Minute}).
+//		Repositories.SyncRepository("zoo")
+//
+// The returned client shares the transport of c and is configured like c at
+// the time of the call; later changes to either are not shared.
+func (c *Client) WithOptions(opt *RequestOptions) *Client {
+	c.mu.RLock()
+	d := &Client{
+		client:             c.client,
+		transport:          c.transport,
+		base:               c.base,
+		cache:              c.cache,
+		middlewares:        c.middlewares,
+		DisableSsl:         c.DisableSsl,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+		StrictChecksums:    c.StrictChecksums,
+		baseURL:            c.baseURL,
+		UserAgent:          c.UserAgent,
+		auth:               c.auth,
+		retry:              c.retry,
+		limiter:            c.limiter,
+		hooks:              c.hooks,
+		metrics:            c.metrics,
+		server:             c.server,
+		timeout:            c.timeout,
+		ctx:                c.ctx,
+	}
+	c.mu.RUnlock()
+
+	if opt != nil {
+		if opt.Context != nil {
+			d.ctx = opt.Context
+		}
+		switch {
+		case opt.Timeout > 0:
+			d.timeout = opt.Timeout
+		case opt.Timeout < 0:
+			d.timeout = 0
+		}
+	}
+
+	d.initServices()
+	return d
+}
+
+// withContext returns the request with the context of the client applied,
+// unless the request has its own.
+func (c *Client) withContext(req *http.Request) *http.Request {
+	if c.ctx != nil && req.Context() == context.Background() {
+		return req.WithContext(c.ctx)
+	}
+	return req
+}
+
+// withDeadline returns the request with the context of the client and its
+// timeout applied. The cancel function releases the context once the
+// response is read.
+func (c *Client) withDeadline(req *http.Request) (*http.Request, context.CancelFunc) {
+	c.mu.RLock()
+	timeout := c.timeout
+	c.mu.RUnlock()
+
+	req = c.withContext(req)
+	if timeout <= 0 {
+		return req, func() {}
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	return req.WithContext(ctx), cancel
+}
@@ -0,0 +1,210 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy retries requests failing with a 5xx or 429 status or with a
+// transient network error. POST requests are only retried on 429 and 503,
+// when pulp did not process them.
+type RetryPolicy struct {
+	// number of attempts including the first one, 1 disables retries
+	MaxAttempts int
+
+	// the backoff starts at InitialBackoff and is multiplied by Multiplier
+	// after each attempt, up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+
+	// randomizes each backoff by +/- Jitter (a fraction between 0 and 1)
+	Jitter float64
+
+	// waits as long as the server asks in the Retry-After header
+	RespectRetryAfter bool
+}
+
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:       4,
+		InitialBackoff:    500 * time.Millisecond,
+		MaxBackoff:        10 * time.Second,
+		Multiplier:        2,
+		Jitter:            0.2,
+		RespectRetryAfter: true,
+	}
+}
+
+// WithRetryPolicy enables retries, a nil policy uses DefaultRetryPolicy.
+func WithRetryPolicy(p *RetryPolicy) ClientOption {
+	return func(c *Client) error {
+		if p == nil {
+			p = DefaultRetryPolicy()
+		}
+		c.SetRetryPolicy(p)
+		return nil
+	}
+}
+
+// SetRetryPolicy sets the retry policy of the client, nil disables retries.
+func (c *Client) SetRetryPolicy(p *RetryPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.retry = p
+}
+
+func (p *RetryPolicy) shouldRetry(req *http.Request, resp *http.Response, err error, attempt int) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	if req.Body != nil && req.GetBody == nil {
+		return false
+	}
+
+	if err != nil {
+		return req.Method != "POST" && isTransientError(err)
+	}
+
+	switch c := resp.StatusCode; {
+	case c == http.StatusTooManyRequests || c == http.StatusServiceUnavailable:
+		return true
+	case c >= 500:
+		return req.Method != "POST"
+	}
+	return false
+}
+
+func (p *RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if p.RespectRetryAfter && resp != nil {
+		if d, ok := retryAfter(resp); ok {
+			return d
+		}
+	}
+
+	m := p.Multiplier
+	if m < 1 {
+		m = 2
+	}
+
+	d := float64(p.InitialBackoff) * math.Pow(m, float64(attempt-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
+
+// retryAfter parses the Retry-After header, given in seconds or as a date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	h := resp.Header.Get("Retry-After")
+	if h == "" {
+		return 0, false
+	}
+
+	if s, err := strconv.Atoi(h); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(h); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+func isTransientError(err error) bool {
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// do sends the request with hc, retrying it according to the retry policy.
+func (c *Client) do(hc *http.Client, req *http.Request) (*http.Response, error) {
+	c.mu.RLock()
+	retry, limiter := c.retry, c.limiter
+	c.mu.RUnlock()
+
+	for attempt := 1; ; attempt++ {
+		if limiter != nil {
+			if err := limiter.Wait(req.Context()); err != nil {
+				return nil, timeoutError(err)
+			}
+		}
+
+		// signed for each attempt
+		areq, err := c.authenticate(req)
+		if err != nil {
+			return nil, err
+		}
+
+		c.onRequest(areq)
+		start := time.Now()
+		resp, err := hc.Do(areq)
+		if err != nil {
+			c.onError(areq, err)
+		} else {
+			c.onResponse(areq, resp, time.Since(start))
+		}
+
+		if !retry.shouldRetry(areq, resp, err, attempt) {
+			return resp, timeoutError(err)
+		}
+
+		wait := retry.backoff(attempt, resp)
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			t.Stop()
+			return nil, timeoutError(req.Context().Err())
+		case <-t.C:
+		}
+	}
+}
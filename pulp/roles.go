@@ -0,0 +1,161 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"fmt"
+)
+
+type RolesService struct {
+	client *Client
+}
+
+type Role struct {
+	Id          string              `json:"id"`
+	DisplayName string              `json:"display_name"`
+	Description string              `json:"description"`
+	Users       []string            `json:"users"`
+	Permissions map[string][]string `json:"permissions"`
+	Href        string              `json:"_href"`
+}
+
+func (r Role) String() string {
+	return Stringify(r)
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/role/retrieval.html
+func (s *RolesService) ListRoles() ([]*Role, *Response, error) {
+	req, err := s.client.NewRequest("GET", "roles/", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var r []*Role
+	resp, err := s.client.Do(req, &r)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return r, resp, err
+}
+
+func (s *RolesService) GetRole(role string) (*Role, *Response, error) {
+	u := fmt.Sprintf("roles/%s/", role)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := new(Role)
+	resp, err := s.client.Do(req, r)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return r, resp, err
+}
+
+type CreateRoleOptions struct {
+	RoleId      string `json:"role_id"`
+	DisplayName string `json:"display_name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/role/cud.html
+func (s *RolesService) CreateRole(opt *CreateRoleOptions) (*Role, *Response, error) {
+	req, err := s.client.NewRequest("POST", "roles/", opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := new(Role)
+	resp, err := s.client.Do(req, r)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return r, resp, err
+}
+
+// only the non nil fields are updated
+type UpdateRoleOptions struct {
+	DisplayName *string `json:"display_name,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+type updateRoleRequest struct {
+	Delta *UpdateRoleOptions `json:"delta"`
+}
+
+func (s *RolesService) UpdateRole(role string, opt *UpdateRoleOptions) (*Role, *Response, error) {
+	u := fmt.Sprintf("roles/%s/", role)
+
+	req, err := s.client.NewRequest("PUT", u, &updateRoleRequest{Delta: opt})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := new(Role)
+	resp, err := s.client.Do(req, r)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return r, resp, err
+}
+
+func (s *RolesService) DeleteRole(role string) (*Response, error) {
+	u := fmt.Sprintf("roles/%s/", role)
+
+	req, err := s.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
+
+type grantRoleRequest struct {
+	Login string `json:"login"`
+}
+
+// GrantRole adds the user to the role.
+func (s *RolesService) GrantRole(role string, login string) (*Response, error) {
+	u := fmt.Sprintf("roles/%s/users/", role)
+
+	req, err := s.client.NewRequest("POST", u, &grantRoleRequest{Login: login})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
+
+// RevokeRole removes the user from the role.
+func (s *RolesService) RevokeRole(role string, login string) (*Response, error) {
+	u := fmt.Sprintf("roles/%s/users/%s/", role, login)
+
+	req, err := s.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
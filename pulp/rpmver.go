@@ -0,0 +1,268 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NEVRA identifies a version of an rpm by name, epoch, version, release and
+// arch.
+type NEVRA struct {
+	Name    string
+	Epoch   string
+	Version string
+	Release string
+	Arch    string
+}
+
+// ParseNEVRA parses name-[epoch:]version-release.arch, like
+// zsh-0:5.0.2-14.el7.x86_64.
+func ParseNEVRA(s string) (*NEVRA, error) {
+	n := new(NEVRA)
+	invalid := fmt.Errorf("pulp: invalid nevra %q", s)
+
+	rest := s
+	i := strings.LastIndex(rest, ".")
+	if i < 0 {
+		return nil, invalid
+	}
+	rest, n.Arch = rest[:i], rest[i+1:]
+
+	i = strings.LastIndex(rest, "-")
+	if i < 0 {
+		return nil, invalid
+	}
+	rest, n.Release = rest[:i], rest[i+1:]
+
+	i = strings.LastIndex(rest, "-")
+	if i < 0 {
+		return nil, invalid
+	}
+	n.Name, n.Version = rest[:i], rest[i+1:]
+
+	if i := strings.Index(n.Version, ":"); i >= 0 {
+		n.Epoch, n.Version = n.Version[:i], n.Version[i+1:]
+	}
+
+	if n.Name == "" || n.Version == "" || n.Release == "" || n.Arch == "" {
+		return nil, invalid
+	}
+	return n, nil
+}
+
+func (n NEVRA) String() string {
+	return n.Name + "-" + n.EVR() + "." + n.Arch
+}
+
+// EVR returns [epoch:]version-release, the epoch is left out if 0.
+func (n *NEVRA) EVR() string {
+	evr := n.Version + "-" + n.Release
+	if n.Epoch != "" && n.Epoch != "0" {
+		evr = n.Epoch + ":" + evr
+	}
+	return evr
+}
+
+// Compare compares the epoch, version and release of two rpms like rpm
+// does, it returns -1, 0 or 1 if n is older, the same or newer than o. The
+// names and archs are not compared.
+func (n *NEVRA) Compare(o *NEVRA) int {
+	if c := compareEpoch(n.Epoch, o.Epoch); c != 0 {
+		return c
+	}
+	if c := RpmVerCmp(n.Version, o.Version); c != 0 {
+		return c
+	}
+	return RpmVerCmp(n.Release, o.Release)
+}
+
+func compareEpoch(a string, b string) int {
+	ea, _ := strconv.Atoi(a)
+	eb, _ := strconv.Atoi(b)
+	switch {
+	case ea < eb:
+		return -1
+	case ea > eb:
+		return 1
+	}
+	return 0
+}
+
+func (r *RpmUnit) NEVRA() *NEVRA {
+	return &NEVRA{
+		Name:    r.Name,
+		Epoch:   r.Epoch,
+		Version: r.Version,
+		Release: r.Release,
+		Arch:    r.Arch,
+	}
+}
+
+// NEVRA returns the nevra of an rpm or srpm unit, or nil.
+func (u *Unit) NEVRA() *NEVRA {
+	if r := u.Rpm(); r != nil {
+		return r.NEVRA()
+	}
+	return nil
+}
+
+// RpmVerCmp compares two version or release strings with the rpmvercmp
+// algorithm of rpm, including ~ sorting before and ^ after anything. It
+// returns -1, 0 or 1 if a is older, the same or newer than b.
+func RpmVerCmp(a string, b string) int {
+	if a == b {
+		return 0
+	}
+
+	for {
+		a = strings.TrimLeftFunc(a, isVersionSeparator)
+		b = strings.TrimLeftFunc(b, isVersionSeparator)
+
+		// a tilde sorts before anything, even the end of the version
+		if strings.HasPrefix(a, "~") || strings.HasPrefix(b, "~") {
+			if !strings.HasPrefix(a, "~") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "~") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		// a caret sorts after the end of the version, but before anything
+		// else
+		if strings.HasPrefix(a, "^") || strings.HasPrefix(b, "^") {
+			if a == "" {
+				return -1
+			}
+			if b == "" {
+				return 1
+			}
+			if !strings.HasPrefix(a, "^") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "^") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		if a == "" || b == "" {
+			break
+		}
+
+		numeric := isDigit(rune(a[0]))
+		segment := isAlpha
+		if numeric {
+			segment = isDigit
+		}
+
+		sa, sb := leadingSegment(a, segment), leadingSegment(b, segment)
+		a, b = a[len(sa):], b[len(sb):]
+
+		// segments of different kinds, numeric ones are newer
+		if sb == "" {
+			if numeric {
+				return 1
+			}
+			return -1
+		}
+
+		if numeric {
+			sa = strings.TrimLeft(sa, "0")
+			sb = strings.TrimLeft(sb, "0")
+			if len(sa) != len(sb) {
+				if len(sa) > len(sb) {
+					return 1
+				}
+				return -1
+			}
+		}
+
+		if c := strings.Compare(sa, sb); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case a == "" && b == "":
+		return 0
+	case a == "":
+		return -1
+	}
+	return 1
+}
+
+func leadingSegment(s string, in func(rune) bool) string {
+	i := strings.IndexFunc(s, func(r rune) bool { return !in(r) })
+	if i < 0 {
+		return s
+	}
+	return s[:i]
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isAlpha(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func isVersionSeparator(r rune) bool {
+	return !isDigit(r) && !isAlpha(r) && r != '~' && r != '^'
+}
+
+// LatestVersions keeps the n newest versions of each rpm of the units, by
+// type, name and arch. Units which are not rpms are kept. The order of the
+// units is preserved.
+func LatestVersions(units []*Unit, n int) []*Unit {
+	groups := make(map[string][]*Unit)
+	for _, u := range units {
+		if nevra := u.NEVRA(); nevra != nil {
+			key := u.UnitTypeId + ":" + nevra.Name + "." + nevra.Arch
+			groups[key] = append(groups[key], u)
+		}
+	}
+
+	drop := make(map[*Unit]bool)
+	for _, g := range groups {
+		if len(g) <= n {
+			continue
+		}
+		sort.SliceStable(g, func(i, j int) bool {
+			return g[i].NEVRA().Compare(g[j].NEVRA()) > 0
+		})
+		for _, u := range g[n:] {
+			drop[u] = true
+		}
+	}
+
+	var latest []*Unit
+	for _, u := range units {
+		if !drop[u] {
+			latest = append(latest, u)
+		}
+	}
+	return latest
+}
@@ -0,0 +1,197 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/msutter/go-pulp/pulp"
+)
+
+// the cases of tests/rpmvercmp.at in rpm
+var rpmVerCmpTests = []struct {
+	a, b string
+	want int
+}{
+	{"1.0", "1.0", 0},
+	{"1.0", "2.0", -1},
+	{"2.0", "1.0", 1},
+	{"2.0.1", "2.0.1", 0},
+	{"2.0", "2.0.1", -1},
+	{"2.0.1", "2.0", 1},
+	{"2.0.1a", "2.0.1a", 0},
+	{"2.0.1a", "2.0.1", 1},
+	{"2.0.1", "2.0.1a", -1},
+	{"5.5p1", "5.5p1", 0},
+	{"5.5p1", "5.5p2", -1},
+	{"5.5p2", "5.5p1", 1},
+	{"5.5p10", "5.5p10", 0},
+	{"5.5p1", "5.5p10", -1},
+	{"5.5p10", "5.5p1", 1},
+	{"10xyz", "10.1xyz", -1},
+	{"10.1xyz", "10xyz", 1},
+	{"xyz10", "xyz10", 0},
+	{"xyz10", "xyz10.1", -1},
+	{"xyz10.1", "xyz10", 1},
+	{"xyz.4", "xyz.4", 0},
+	{"xyz.4", "8", -1},
+	{"8", "xyz.4", 1},
+	{"xyz.4", "2", -1},
+	{"2", "xyz.4", 1},
+	{"5.5p2", "5.6p1", -1},
+	{"5.6p1", "5.5p2", 1},
+	{"5.6p1", "6.5p1", -1},
+	{"6.5p1", "5.6p1", 1},
+	{"6.0.rc1", "6.0", 1},
+	{"6.0", "6.0.rc1", -1},
+	{"10b2", "10a1", 1},
+	{"10a2", "10b2", -1},
+	{"1.0aa", "1.0aa", 0},
+	{"1.0a", "1.0aa", -1},
+	{"1.0aa", "1.0a", 1},
+	{"10.0001", "10.0001", 0},
+	{"10.0001", "10.1", 0},
+	{"10.1", "10.0001", 0},
+	{"10.0001", "10.0039", -1},
+	{"10.0039", "10.0001", 1},
+	{"4.999.9", "5.0", -1},
+	{"5.0", "4.999.9", 1},
+	{"20101121", "20101121", 0},
+	{"20101121", "20101122", -1},
+	{"20101122", "20101121", 1},
+	{"2_0", "2_0", 0},
+	{"2.0", "2_0", 0},
+	{"2_0", "2.0", 0},
+	{"a", "a", 0},
+	{"a+", "a+", 0},
+	{"a+", "a_", 0},
+	{"a_", "a+", 0},
+	{"+a", "+a", 0},
+	{"+a", "_a", 0},
+	{"_a", "+a", 0},
+	{"+_", "+_", 0},
+	{"_+", "+_", 0},
+	{"_+", "_", 0},
+	{"+", "_", 0},
+	{"_", "+", 0},
+	{"1.0~rc1", "1.0~rc1", 0},
+	{"1.0~rc1", "1.0", -1},
+	{"1.0", "1.0~rc1", 1},
+	{"1.0~rc1", "1.0~rc2", -1},
+	{"1.0~rc2", "1.0~rc1", 1},
+	{"1.0~rc1~git123", "1.0~rc1~git123", 0},
+	{"1.0~rc1~git123", "1.0~rc1", -1},
+	{"1.0~rc1", "1.0~rc1~git123", 1},
+	{"1.0^", "1.0^", 0},
+	{"1.0^", "1.0", 1},
+	{"1.0", "1.0^", -1},
+	{"1.0^git1", "1.0^git1", 0},
+	{"1.0^git1", "1.0", 1},
+	{"1.0", "1.0^git1", -1},
+	{"1.0^git1", "1.0^git2", -1},
+	{"1.0^git2", "1.0^git1", 1},
+	{"1.0^git1", "1.01", -1},
+	{"1.01", "1.0^git1", 1},
+	{"1.0^20160101", "1.0^20160101", 0},
+	{"1.0^20160101", "1.0.1", -1},
+	{"1.0.1", "1.0^20160101", 1},
+	{"1.0^20160101^git1", "1.0^20160101^git1", 0},
+	{"1.0^20160102", "1.0^20160101^git1", 1},
+	{"1.0^20160101^git1", "1.0^20160102", -1},
+	{"1.0~rc1^git1", "1.0~rc1^git1", 0},
+	{"1.0~rc1^git1", "1.0~rc1", 1},
+	{"1.0~rc1", "1.0~rc1^git1", -1},
+	{"1.0^git1~pre", "1.0^git1~pre", 0},
+	{"1.0^git1", "1.0^git1~pre", 1},
+	{"1.0^git1~pre", "1.0^git1", -1},
+}
+
+func TestRpmVerCmp(t *testing.T) {
+	for _, tt := range rpmVerCmpTests {
+		if got := pulp.RpmVerCmp(tt.a, tt.b); got != tt.want {
+			t.Errorf("RpmVerCmp(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNEVRACompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"zsh-5.0.2-14.el7.x86_64", "zsh-0:5.0.2-14.el7.x86_64", 0},
+		{"zsh-1:4.0-1.el7.x86_64", "zsh-5.0.2-14.el7.x86_64", 1},
+		{"zsh-5.0.2-14.el7.x86_64", "zsh-5.0.2-9.el7.x86_64", 1},
+		{"zsh-5.0.2-14.el7.x86_64", "zsh-5.0.10-1.el7.x86_64", -1},
+	}
+
+	for _, tt := range tests {
+		a, err := pulp.ParseNEVRA(tt.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := pulp.ParseNEVRA(tt.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := a.Compare(b); got != tt.want {
+			t.Errorf("%s.Compare(%s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestParseNEVRA(t *testing.T) {
+	n, err := pulp.ParseNEVRA("zsh-0:5.0.2-14.el7.x86_64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &pulp.NEVRA{Name: "zsh", Epoch: "0", Version: "5.0.2", Release: "14.el7", Arch: "x86_64"}
+	if !reflect.DeepEqual(n, want) {
+		t.Errorf("ParseNEVRA() = %+v, want %+v", n, want)
+	}
+
+	for _, s := range []string{"zsh", "zsh.x86_64", "zsh-5.0.2.x86_64", "-5.0.2-14.el7.x86_64"} {
+		_, err := pulp.ParseNEVRA(s)
+		if err == nil {
+			t.Errorf("ParseNEVRA(%q) succeeded", s)
+			continue
+		}
+		if want := `pulp: invalid nevra "` + s + `"`; err.Error() != want {
+			t.Errorf("ParseNEVRA(%q) error = %q, want %q", s, err, want)
+		}
+	}
+}
+
+func TestLatestVersions(t *testing.T) {
+	rpm := func(typeId, name, version, arch string) *pulp.Unit {
+		return &pulp.Unit{UnitTypeId: typeId, Metadata: &pulp.RpmUnit{Name: name, Version: version, Release: "1", Arch: arch}}
+	}
+
+	zsh1 := rpm(pulp.RpmUnitType, "zsh", "5.0.2", "x86_64")
+	zsh2 := rpm(pulp.RpmUnitType, "zsh", "5.0.10", "x86_64")
+	zsh3 := rpm(pulp.RpmUnitType, "zsh", "5.0.9", "x86_64")
+	zshI686 := rpm(pulp.RpmUnitType, "zsh", "5.0.2", "i686")
+	zshSrc := rpm(pulp.SrpmUnitType, "zsh", "5.0.2", "src")
+	erratum := &pulp.Unit{UnitTypeId: pulp.ErratumUnitType}
+
+	got := pulp.LatestVersions([]*pulp.Unit{zsh1, erratum, zsh2, zshI686, zsh3, zshSrc}, 1)
+	want := []*pulp.Unit{erratum, zsh2, zshI686, zshSrc}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LatestVersions() kept %d units, want %d", len(got), len(want))
+	}
+}
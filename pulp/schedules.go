@@ -0,0 +1,489 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/repo/sync.html#scheduling-a-sync
+type Schedule struct {
+	Id                  string                 `json:"_id"`
+	Href                string                 `json:"_href"`
+	Schedule            string                 `json:"schedule"`
+	FailureThreshold    *int                   `json:"failure_threshold"`
+	Enabled             bool                   `json:"enabled"`
+	ConsecutiveFailures int                    `json:"consecutive_failures"`
+	RemainingRuns       *int                   `json:"remaining_runs"`
+	FirstRun            PulpTime               `json:"first_run"`
+	LastRunAt           PulpTime               `json:"last_run_at"`
+	NextRun             PulpTime               `json:"next_run"`
+	TotalRunCount       int                    `json:"total_run_count"`
+	OverrideConfig      map[string]interface{} `json:"override_config"`
+	Resource            string                 `json:"resource"`
+}
+
+func (s Schedule) String() string {
+	return Stringify(s)
+}
+
+// Interval parses the ISO8601 schedule of the schedule.
+func (s *Schedule) Interval() (*ScheduleInterval, error) {
+	return ParseScheduleInterval(s.Schedule)
+}
+
+// ScheduleInterval is an ISO8601 recurring time interval, like
+// R5/2016-04-04T00:00:00Z/P1D. A zero Recurrences repeats forever and a
+// zero Start lets pulp start the schedule right away.
+type ScheduleInterval struct {
+	Recurrences int
+	Start       time.Time
+	Period      Period
+}
+
+func (i ScheduleInterval) String() string {
+	var parts []string
+	if i.Recurrences > 0 {
+		parts = append(parts, "R"+strconv.Itoa(i.Recurrences))
+	}
+	if !i.Start.IsZero() {
+		parts = append(parts, i.Start.UTC().Format(time.RFC3339))
+	}
+	parts = append(parts, i.Period.String())
+	return strings.Join(parts, "/")
+}
+
+func ParseScheduleInterval(s string) (*ScheduleInterval, error) {
+	i := new(ScheduleInterval)
+	var err error
+
+	for _, part := range strings.Split(s, "/") {
+		switch {
+		case strings.HasPrefix(part, "R"):
+			if part == "R" {
+				continue
+			}
+			if i.Recurrences, err = strconv.Atoi(part[1:]); err != nil || i.Recurrences < 0 {
+				return nil, fmt.Errorf("pulp: invalid schedule recurrences %q", part)
+			}
+		case strings.HasPrefix(part, "P"):
+			if i.Period, err = ParsePeriod(part); err != nil {
+				return nil, err
+			}
+		default:
+			if i.Start, err = time.Parse(time.RFC3339, part); err != nil {
+				return nil, fmt.Errorf("pulp: invalid schedule start time %q", part)
+			}
+		}
+	}
+
+	if i.Period.IsZero() {
+		return nil, fmt.Errorf("pulp: schedule %q has no period", s)
+	}
+	return i, nil
+}
+
+// Period is an ISO8601 duration, like P1DT12H.
+type Period struct {
+	Years   int
+	Months  int
+	Weeks   int
+	Days    int
+	Hours   int
+	Minutes int
+	Seconds int
+}
+
+var periodRegexp = regexp.MustCompile(`^P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+func ParsePeriod(s string) (Period, error) {
+	var p Period
+
+	m := periodRegexp.FindStringSubmatch(s)
+	if m == nil || s == "P" || strings.HasSuffix(s, "T") {
+		return p, fmt.Errorf("pulp: invalid ISO8601 period %q", s)
+	}
+
+	fields := []*int{&p.Years, &p.Months, &p.Weeks, &p.Days, &p.Hours, &p.Minutes, &p.Seconds}
+	for n, f := range fields {
+		if m[n+1] != "" {
+			*f, _ = strconv.Atoi(m[n+1])
+		}
+	}
+	return p, nil
+}
+
+// PeriodFromDuration converts a duration to a period of days, hours,
+// minutes and seconds.
+func PeriodFromDuration(d time.Duration) Period {
+	s := int(d / time.Second)
+	return Period{
+		Days:    s / 86400,
+		Hours:   s % 86400 / 3600,
+		Minutes: s % 3600 / 60,
+		Seconds: s % 60,
+	}
+}
+
+func (p Period) IsZero() bool {
+	return p == Period{}
+}
+
+func (p Period) String() string {
+	s := "P"
+	for _, f := range []struct {
+		v int
+		u string
+	}{{p.Years, "Y"}, {p.Months, "M"}, {p.Weeks, "W"}, {p.Days, "D"}} {
+		if f.v != 0 {
+			s += strconv.Itoa(f.v) + f.u
+		}
+	}
+
+	t := ""
+	for _, f := range []struct {
+		v int
+		u string
+	}{{p.Hours, "H"}, {p.Minutes, "M"}, {p.Seconds, "S"}} {
+		if f.v != 0 {
+			t += strconv.Itoa(f.v) + f.u
+		}
+	}
+	if t != "" {
+		s += "T" + t
+	}
+
+	if s == "P" {
+		return "PT0S"
+	}
+	return s
+}
+
+type ScheduleOptions struct {
+	Schedule         string      `json:"schedule"`
+	FailureThreshold int         `json:"failure_threshold,omitempty"`
+	Enabled          *bool       `json:"enabled,omitempty"`
+	OverrideConfig   interface{} `json:"override_config,omitempty"`
+}
+
+// only the non nil fields are updated
+type UpdateScheduleOptions struct {
+	Schedule         *string     `json:"schedule,omitempty"`
+	FailureThreshold *int        `json:"failure_threshold,omitempty"`
+	Enabled          *bool       `json:"enabled,omitempty"`
+	OverrideConfig   interface{} `json:"override_config,omitempty"`
+}
+
+func syncSchedulesPath(repository string, importer string) string {
+	return fmt.Sprintf("repositories/%s/importers/%s/schedules/sync/", repository, importer)
+}
+
+func (s *RepositoriesService) ListSyncSchedules(repository string, importer string) ([]*Schedule, *Response, error) {
+	return s.client.listSchedules(syncSchedulesPath(repository, importer))
+}
+
+func (s *RepositoriesService) GetSyncSchedule(repository string, importer string, schedule string) (*Schedule, *Response, error) {
+	return s.client.getSchedule(syncSchedulesPath(repository, importer) + schedule + "/")
+}
+
+func (s *RepositoriesService) CreateSyncSchedule(repository string, importer string, opt *ScheduleOptions) (*Schedule, *Response, error) {
+	return s.client.createSchedule(syncSchedulesPath(repository, importer), opt)
+}
+
+func (s *RepositoriesService) UpdateSyncSchedule(repository string, importer string, schedule string, opt *UpdateScheduleOptions) (*Schedule, *Response, error) {
+	return s.client.updateSchedule(syncSchedulesPath(repository, importer)+schedule+"/", opt)
+}
+
+func (s *RepositoriesService) DeleteSyncSchedule(repository string, importer string, schedule string) (*Response, error) {
+	return s.client.deleteSchedule(syncSchedulesPath(repository, importer) + schedule + "/")
+}
+
+func (c *Client) listSchedules(u string) ([]*Schedule, *Response, error) {
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sc []*Schedule
+	resp, err := c.Do(req, &sc)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return sc, resp, err
+}
+
+func (c *Client) getSchedule(u string) (*Schedule, *Response, error) {
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sc := new(Schedule)
+	resp, err := c.Do(req, sc)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return sc, resp, err
+}
+
+func (c *Client) createSchedule(u string, opt *ScheduleOptions) (*Schedule, *Response, error) {
+	if opt == nil || opt.Schedule == "" {
+		return nil, nil, errors.New("a schedule is required")
+	}
+
+	req, err := c.NewRequest("POST", u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sc := new(Schedule)
+	resp, err := c.Do(req, sc)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return sc, resp, err
+}
+
+func (c *Client) updateSchedule(u string, opt *UpdateScheduleOptions) (*Schedule, *Response, error) {
+	req, err := c.NewRequest("PUT", u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sc := new(Schedule)
+	resp, err := c.Do(req, sc)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return sc, resp, err
+}
+
+func (c *Client) deleteSchedule(u string) (*Response, error) {
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Do(req, nil)
+}
+
+func publishSchedulesPath(repository string, distributor string) string {
+	return fmt.Sprintf("repositories/%s/distributors/%s/schedules/publish/", repository, distributor)
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/repo/publish.html#scheduling-a-publish
+func (s *RepositoriesService) ListPublishSchedules(repository string, distributor string) ([]*Schedule, *Response, error) {
+	return s.client.listSchedules(publishSchedulesPath(repository, distributor))
+}
+
+func (s *RepositoriesService) GetPublishSchedule(repository string, distributor string, schedule string) (*Schedule, *Response, error) {
+	return s.client.getSchedule(publishSchedulesPath(repository, distributor) + schedule + "/")
+}
+
+func (s *RepositoriesService) CreatePublishSchedule(repository string, distributor string, opt *ScheduleOptions) (*Schedule, *Response, error) {
+	return s.client.createSchedule(publishSchedulesPath(repository, distributor), opt)
+}
+
+func (s *RepositoriesService) UpdatePublishSchedule(repository string, distributor string, schedule string, opt *UpdateScheduleOptions) (*Schedule, *Response, error) {
+	return s.client.updateSchedule(publishSchedulesPath(repository, distributor)+schedule+"/", opt)
+}
+
+func (s *RepositoriesService) DeletePublishSchedule(repository string, distributor string, schedule string) (*Response, error) {
+	return s.client.deleteSchedule(publishSchedulesPath(repository, distributor) + schedule + "/")
+}
+
+const (
+	SyncScheduleKind      = "sync"
+	PublishScheduleKind   = "publish"
+	InstallScheduleKind   = "install"
+	UpdateScheduleKind    = "update"
+	UninstallScheduleKind = "uninstall"
+)
+
+// SchedulesService enumerates the schedules of all the repositories and
+// consumers, which pulp only lists per importer, distributor or consumer.
+type SchedulesService struct {
+	client *Client
+}
+
+// ScheduledAction is a schedule with the resource it runs on. Kind is one
+// of the *ScheduleKind constants; RepoId and ImporterId or DistributorId
+// are set for repository schedules, ConsumerId for consumer ones.
+type ScheduledAction struct {
+	*Schedule
+	Kind          string
+	RepoId        string
+	ImporterId    string
+	DistributorId string
+	ConsumerId    string
+}
+
+// Empty fields select everything: all kinds of schedules, of all the
+// repositories and consumers. Consumer schedules are only listed when
+// Consumers is set, as it takes a request per consumer and action.
+type ListSchedulesOptions struct {
+	Kinds       []string
+	RepoIds     []string
+	ConsumerIds []string
+	Consumers   bool
+}
+
+func consumerSchedulesPath(consumer string, action string) string {
+	return fmt.Sprintf("consumers/%s/schedules/content/%s/", consumer, action)
+}
+
+// ListSchedules lists the schedules selected by the options.
+func (s *SchedulesService) ListSchedules(opt *ListSchedulesOptions) ([]*ScheduledAction, error) {
+	if opt == nil {
+		opt = &ListSchedulesOptions{}
+	}
+	kinds := stringSet(opt.Kinds)
+	repoIds := stringSet(opt.RepoIds)
+	consumerIds := stringSet(opt.ConsumerIds)
+
+	var actions []*ScheduledAction
+
+	if kinds.has(SyncScheduleKind) || kinds.has(PublishScheduleKind) {
+		repos, _, err := s.client.Repositories.ListRepositories(&ListRepositoriesOptions{Details: true})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range repos {
+			if !repoIds.has(r.Id) {
+				continue
+			}
+
+			if kinds.has(SyncScheduleKind) {
+				for _, i := range r.Importers {
+					schedules, _, err := s.client.Repositories.ListSyncSchedules(r.Id, i.Id)
+					if err != nil {
+						return nil, err
+					}
+					for _, sc := range schedules {
+						actions = append(actions, &ScheduledAction{Schedule: sc, Kind: SyncScheduleKind, RepoId: r.Id, ImporterId: i.Id})
+					}
+				}
+			}
+
+			if kinds.has(PublishScheduleKind) {
+				for _, d := range r.Distributors {
+					schedules, _, err := s.client.Repositories.ListPublishSchedules(r.Id, d.Id)
+					if err != nil {
+						return nil, err
+					}
+					for _, sc := range schedules {
+						actions = append(actions, &ScheduledAction{Schedule: sc, Kind: PublishScheduleKind, RepoId: r.Id, DistributorId: d.Id})
+					}
+				}
+			}
+		}
+	}
+
+	if !opt.Consumers && len(opt.ConsumerIds) == 0 {
+		return actions, nil
+	}
+
+	consumers, _, err := s.client.Consumers.ListConsumers(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range consumers {
+		if !consumerIds.has(c.Id) {
+			continue
+		}
+
+		for _, kind := range []string{InstallScheduleKind, UpdateScheduleKind, UninstallScheduleKind} {
+			if !kinds.has(kind) {
+				continue
+			}
+
+			schedules, _, err := s.client.listSchedules(consumerSchedulesPath(c.Id, kind))
+			if err != nil {
+				return nil, err
+			}
+			for _, sc := range schedules {
+				actions = append(actions, &ScheduledAction{Schedule: sc, Kind: kind, ConsumerId: c.Id})
+			}
+		}
+	}
+
+	return actions, nil
+}
+
+// UpdateSchedule updates a listed schedule.
+func (s *SchedulesService) UpdateSchedule(a *ScheduledAction, opt *UpdateScheduleOptions) (*Schedule, *Response, error) {
+	return s.client.updateSchedule(s.schedulePath(a), opt)
+}
+
+// SetEnabled enables or disables the schedules. The schedules are all
+// updated, the error is a *BulkError keyed by schedule id listing the
+// failed updates.
+func (s *SchedulesService) SetEnabled(actions []*ScheduledAction, enabled bool) error {
+	errs := make(map[string]error)
+	for _, a := range actions {
+		sc, _, err := s.UpdateSchedule(a, &UpdateScheduleOptions{Enabled: Bool(enabled)})
+		if err != nil {
+			errs[a.Id] = err
+			continue
+		}
+		a.Schedule = sc
+	}
+
+	if len(errs) > 0 {
+		return &BulkError{Errors: errs}
+	}
+	return nil
+}
+
+// schedulePath returns the path of the schedule relative to the api.
+func (s *SchedulesService) schedulePath(a *ScheduledAction) string {
+	switch a.Kind {
+	case SyncScheduleKind:
+		return syncSchedulesPath(a.RepoId, a.ImporterId) + a.Id + "/"
+	case PublishScheduleKind:
+		return publishSchedulesPath(a.RepoId, a.DistributorId) + a.Id + "/"
+	}
+	return consumerSchedulesPath(a.ConsumerId, a.Kind) + a.Id + "/"
+}
+
+// stringSet is a set of strings, an empty set holds all of them.
+type stringSet []string
+
+func (s stringSet) has(v string) bool {
+	if len(s) == 0 {
+		return true
+	}
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/msutter/go-pulp/pulp"
+)
+
+func TestParsePeriod(t *testing.T) {
+	tests := []struct {
+		s    string
+		want pulp.Period
+		str  string
+	}{
+		{"P1D", pulp.Period{Days: 1}, "P1D"},
+		{"PT1H", pulp.Period{Hours: 1}, "PT1H"},
+		{"PT30M", pulp.Period{Minutes: 30}, "PT30M"},
+		{"P1M", pulp.Period{Months: 1}, "P1M"},
+		{"P2W", pulp.Period{Weeks: 2}, "P2W"},
+		{"P1DT12H", pulp.Period{Days: 1, Hours: 12}, "P1DT12H"},
+		{"P1Y2M3W4DT5H6M7S", pulp.Period{Years: 1, Months: 2, Weeks: 3, Days: 4, Hours: 5, Minutes: 6, Seconds: 7}, "P1Y2M3W4DT5H6M7S"},
+		{"PT0S", pulp.Period{}, "PT0S"},
+		{"P0DT1H", pulp.Period{Hours: 1}, "PT1H"},
+	}
+
+	for _, tt := range tests {
+		p, err := pulp.ParsePeriod(tt.s)
+		if err != nil {
+			t.Errorf("ParsePeriod(%q) error = %v", tt.s, err)
+			continue
+		}
+		if p != tt.want {
+			t.Errorf("ParsePeriod(%q) = %+v, want %+v", tt.s, p, tt.want)
+		}
+		if got := p.String(); got != tt.str {
+			t.Errorf("ParsePeriod(%q).String() = %q, want %q", tt.s, got, tt.str)
+		}
+	}
+
+	for _, s := range []string{"", "P", "PT", "P1DT", "1D", "P1H", "PT1D", "P1.5D", "P-1D", "P1D2Y"} {
+		if p, err := pulp.ParsePeriod(s); err == nil {
+			t.Errorf("ParsePeriod(%q) = %+v, want an error", s, p)
+		}
+	}
+}
+
+func TestPeriodFromDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "PT0S"},
+		{90 * time.Second, "PT1M30S"},
+		{36 * time.Hour, "P1DT12H"},
+		{7 * 24 * time.Hour, "P7D"},
+	}
+
+	for _, tt := range tests {
+		if got := pulp.PeriodFromDuration(tt.d).String(); got != tt.want {
+			t.Errorf("PeriodFromDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestParseScheduleInterval(t *testing.T) {
+	start := time.Date(2016, 4, 4, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		s    string
+		want pulp.ScheduleInterval
+		str  string
+	}{
+		{"P1D", pulp.ScheduleInterval{Period: pulp.Period{Days: 1}}, "P1D"},
+		{"R5/P1D", pulp.ScheduleInterval{Recurrences: 5, Period: pulp.Period{Days: 1}}, "R5/P1D"},
+		{"R/P1D", pulp.ScheduleInterval{Period: pulp.Period{Days: 1}}, "P1D"},
+		{"2016-04-04T00:00:00Z/PT6H", pulp.ScheduleInterval{Start: start, Period: pulp.Period{Hours: 6}}, "2016-04-04T00:00:00Z/PT6H"},
+		{"R5/2016-04-04T00:00:00Z/P1D", pulp.ScheduleInterval{Recurrences: 5, Start: start, Period: pulp.Period{Days: 1}}, "R5/2016-04-04T00:00:00Z/P1D"},
+		{"R1/2016-04-04T02:00:00+02:00/P1W", pulp.ScheduleInterval{Recurrences: 1, Start: start, Period: pulp.Period{Weeks: 1}}, "R1/2016-04-04T00:00:00Z/P1W"},
+	}
+
+	for _, tt := range tests {
+		i, err := pulp.ParseScheduleInterval(tt.s)
+		if err != nil {
+			t.Errorf("ParseScheduleInterval(%q) error = %v", tt.s, err)
+			continue
+		}
+		if i.Recurrences != tt.want.Recurrences || !i.Start.Equal(tt.want.Start) || i.Period != tt.want.Period {
+			t.Errorf("ParseScheduleInterval(%q) = %+v, want %+v", tt.s, i, tt.want)
+		}
+		if got := i.String(); got != tt.str {
+			t.Errorf("ParseScheduleInterval(%q).String() = %q, want %q", tt.s, got, tt.str)
+		}
+	}
+
+	for _, s := range []string{"", "R5", "R5/2016-04-04T00:00:00Z", "Rx/P1D", "R-1/P1D", "R5/2016-04-04/P1D", "R5/2016-04-04T00:00:00Z/P", "PT0S"} {
+		if i, err := pulp.ParseScheduleInterval(s); err == nil {
+			t.Errorf("ParseScheduleInterval(%q) = %+v, want an error", s, i)
+		}
+	}
+}
+
+func TestScheduleInterval(t *testing.T) {
+	s := &pulp.Schedule{Schedule: "R3/2016-04-04T00:00:00Z/PT12H"}
+	i, err := s.Interval()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.Recurrences != 3 || i.Period != (pulp.Period{Hours: 12}) {
+		t.Errorf("Interval() = %+v", i)
+	}
+}
@@ -0,0 +1,96 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+const pgpSignatureHeader = "-----BEGIN PGP SIGNATURE-----"
+
+// SignatureVerifier verifies the detached signature of data, e.g. with
+// golang.org/x/crypto/openpgp:
+//
+//	func(data, signature []byte) error {
+//		_, err := openpgp.CheckArmoredDetachedSignature(keyring,
+//			bytes.NewReader(data), bytes.NewReader(signature))
+//		return err
+//	}
+type SignatureVerifier func(data []byte, signature []byte) error
+
+// RepodataSignature is the signature of the published repomd.xml of a yum
+// repository. Signed is set when repomd.xml.asc is published and holds an
+// armored signature, Verified when it was checked by a SignatureVerifier.
+// Key is the public key published as repomd.xml.key, if any.
+type RepodataSignature struct {
+	Signed    bool
+	Verified  bool
+	Signature []byte
+	Key       []byte
+}
+
+func (s RepodataSignature) String() string {
+	switch {
+	case s.Verified:
+		return "signed, verified"
+	case s.Signed:
+		return "signed"
+	}
+	return "unsigned"
+}
+
+// CheckRepodataSignature fetches the signature of the published repomd.xml
+// of the repository and verifies it with verify, if not nil. A failed
+// verification returns an error wrapping ErrBadSignature.
+func (c *Client) CheckRepodataSignature(repository string, verify SignatureVerifier) (*RepodataSignature, error) {
+	base, err := c.PublishedRepoPath(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	s := new(RepodataSignature)
+
+	s.Signature, err = c.fetchContent(base + "repodata/repomd.xml.asc")
+	if errors.Is(err, ErrNotFound) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.Signed = bytes.Contains(s.Signature, []byte(pgpSignatureHeader))
+
+	s.Key, err = c.fetchContent(base + "repodata/repomd.xml.key")
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	if verify == nil || !s.Signed {
+		return s, nil
+	}
+
+	repomd, err := c.fetchContent(base + "repodata/repomd.xml")
+	if err != nil {
+		return nil, err
+	}
+	if err := verify(repomd, s.Signature); err != nil {
+		return s, fmt.Errorf("%w: %s repomd.xml: %v", ErrBadSignature, repository, err)
+	}
+	s.Verified = true
+	return s, nil
+}
@@ -0,0 +1,169 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"fmt"
+)
+
+// StatusService reports the health of the pulp server and the content
+// types, importers and distributors provided by its plugins.
+type StatusService struct {
+	client *Client
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/status.html
+type Status struct {
+	ApiVersion          string     `json:"api_version"`
+	DatabaseConnection  Connection `json:"database_connection"`
+	MessagingConnection Connection `json:"messaging_connection"`
+	KnownWorkers        []*Worker  `json:"known_workers"`
+	Versions            struct {
+		PlatformVersion string `json:"platform_version"`
+	} `json:"versions"`
+}
+
+type Connection struct {
+	Connected bool `json:"connected"`
+}
+
+type Worker struct {
+	Name          string   `json:"_id"`
+	LastHeartbeat PulpTime `json:"last_heartbeat"`
+}
+
+func (s Status) String() string {
+	return Stringify(s)
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/server_plugins.html
+type ContentType struct {
+	Id              string     `json:"id"`
+	DisplayName     string     `json:"display_name"`
+	Description     string     `json:"description"`
+	UnitKey         []string   `json:"unit_key"`
+	SearchIndexes   [][]string `json:"search_indexes"`
+	ReferencedTypes []string   `json:"referenced_types"`
+	Href            string     `json:"_href"`
+}
+
+func (t ContentType) String() string {
+	return Stringify(t)
+}
+
+// Plugin is an importer or a distributor, and the content types it
+// supports.
+type Plugin struct {
+	Id          string   `json:"id"`
+	DisplayName string   `json:"display_name"`
+	Types       []string `json:"types"`
+	Href        string   `json:"_href"`
+}
+
+func (p Plugin) String() string {
+	return Stringify(p)
+}
+
+// the status is returned without authentication
+func (s *StatusService) GetStatus() (*Status, *Response, error) {
+	req, err := s.client.NewRequest("GET", "status/", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	st := new(Status)
+	resp, err := s.client.Do(req, st)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return st, resp, err
+}
+
+func (s *StatusService) ListContentTypes() ([]*ContentType, *Response, error) {
+	req, err := s.client.NewRequest("GET", "plugins/types/", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var t []*ContentType
+	resp, err := s.client.Do(req, &t)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return t, resp, err
+}
+
+func (s *StatusService) GetContentType(typeId string) (*ContentType, *Response, error) {
+	u := fmt.Sprintf("plugins/types/%s/", typeId)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	t := new(ContentType)
+	resp, err := s.client.Do(req, t)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return t, resp, err
+}
+
+func (s *StatusService) ListImporterPlugins() ([]*Plugin, *Response, error) {
+	return s.listPlugins("plugins/importers/")
+}
+
+func (s *StatusService) ListDistributorPlugins() ([]*Plugin, *Response, error) {
+	return s.listPlugins("plugins/distributors/")
+}
+
+func (s *StatusService) listPlugins(u string) ([]*Plugin, *Response, error) {
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var p []*Plugin
+	resp, err := s.client.Do(req, &p)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return p, resp, err
+}
+
+// Ping checks that the pulp server is reachable and connected to its
+// database and message broker.
+func (c *Client) Ping() error {
+	st, _, err := c.Status.GetStatus()
+	if err != nil {
+		return err
+	}
+
+	if !st.DatabaseConnection.Connected {
+		return fmt.Errorf("pulp %s is not connected to its database", c.apiURL().Host)
+	}
+	if !st.MessagingConnection.Connected {
+		return fmt.Errorf("pulp %s is not connected to its message broker", c.apiURL().Host)
+	}
+	return nil
+}
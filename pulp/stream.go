@@ -0,0 +1,135 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DoStream sends the request and decodes the JSON array of the response one
+// element at a time, calling decode for each of them. Memory stays bounded
+// by the size of a single element, whatever the size of the response.
+//
+// Like Download it does not apply the client timeout, which would cut off
+// long responses; use a context to bound it.
+func (c *Client) DoStream(req *http.Request, decode func(dec *json.Decoder) error) (*Response, error) {
+	req = withoutCache(c.withContext(req))
+
+	hc := *c.httpClient()
+	hc.Timeout = 0
+
+	resp, err := c.do(&hc, req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	response := newResponse(resp)
+
+	err = CheckResponse(resp)
+	if err != nil {
+		c.onError(req, err)
+		return response, err
+	}
+
+	err = decodeArray(resp.Body, decode)
+	if err != nil {
+		err = timeoutError(err)
+		c.onError(req, err)
+	}
+	return response, err
+}
+
+func decodeArray(r io.Reader, decode func(dec *json.Decoder) error) error {
+	dec := json.NewDecoder(r)
+
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := t.(json.Delim); !ok || d != '[' {
+		return fmt.Errorf("expected a JSON array, got %v", t)
+	}
+
+	for dec.More() {
+		if err := decode(dec); err != nil {
+			return err
+		}
+	}
+
+	_, err = dec.Token()
+	return err
+}
+
+// StreamUnits calls fn for each unit of the repository matching the
+// criteria as it is decoded. Returning an error from fn stops the stream.
+func (s *UnitsService) StreamUnits(repository string, criteria *UnitCriteria, fn func(*Unit) error) (*Response, error) {
+	if criteria == nil {
+		criteria = NewUnitCriteria()
+	}
+
+	u := fmt.Sprintf("repositories/%s/search/units/", repository)
+
+	req, err := s.client.NewRequestWithQuery("POST", u, nil, &unitSearchRequest{Criteria: criteria})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.DoStream(req, func(dec *json.Decoder) error {
+		u := new(Unit)
+		if err := dec.Decode(u); err != nil {
+			return err
+		}
+		return fn(u)
+	})
+}
+
+// StreamTasks calls fn for each task as it is decoded.
+func (s *TasksService) StreamTasks(fn func(*Task) error) (*Response, error) {
+	req, err := s.client.NewRequest("GET", "tasks/", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.DoStream(req, func(dec *json.Decoder) error {
+		t := new(Task)
+		if err := dec.Decode(t); err != nil {
+			return err
+		}
+		return fn(t)
+	})
+}
+
+// StreamRepositories calls fn for each repository as it is decoded.
+func (s *RepositoriesService) StreamRepositories(opt *ListRepositoriesOptions, fn func(*Repository) error) (*Response, error) {
+	req, err := s.client.NewRequest("GET", "repositories/", opt)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.DoStream(req, func(dec *json.Decoder) error {
+		r := new(Repository)
+		if err := dec.Decode(r); err != nil {
+			return err
+		}
+		return fn(r)
+	})
+}
@@ -21,11 +21,14 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"sort"
+	"strings"
 )
 
 // Stringify attempts to create a reasonable string representation of types in
 // the library.  It does things like resolve pointers to their values
-// and omits struct fields with nil values.
+// and omits struct fields with nil values. The values of secrets, like
+// passwords and private keys, are redacted.
 func Stringify(message interface{}) string {
 	var buf bytes.Buffer
 	v := reflect.ValueOf(message)
@@ -33,12 +36,45 @@ func Stringify(message interface{}) string {
 	return buf.String()
 }
 
+const redacted = `"<redacted>"`
+
+// isSecret reports whether the struct field or map key name holds a
+// secret, e.g. ProxyPassword or ssl_client_key.
+func isSecret(name string) bool {
+	n := strings.ToLower(strings.Replace(name, "_", "", -1))
+	switch {
+	case strings.Contains(n, "password"), strings.Contains(n, "secret"), strings.Contains(n, "token"):
+		return true
+	case strings.HasSuffix(n, "clientkey"), strings.HasSuffix(n, "privatekey"):
+		return true
+	}
+	return false
+}
+
+// isZero reports whether the value is empty, empty secrets are not hidden.
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil() || isZero(v.Elem())
+	case reflect.String, reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return false
+}
+
 // stringifyValue was heavily inspired by the goprotobuf library.
 func stringifyValue(w io.Writer, val reflect.Value) {
-	if val.Kind() == reflect.Ptr && val.IsNil() {
+	if !val.IsValid() {
 		w.Write([]byte("<nil>"))
 		return
 	}
+	if (val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface) && val.IsNil() {
+		w.Write([]byte("<nil>"))
+		return
+	}
+	if val.Kind() == reflect.Interface {
+		val = val.Elem()
+	}
 
 	v := reflect.Indirect(val)
 
@@ -46,6 +82,12 @@ func stringifyValue(w io.Writer, val reflect.Value) {
 	case reflect.String:
 		fmt.Fprintf(w, `"%s"`, v)
 	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// raw json or PEM data
+			fmt.Fprintf(w, "%q", v.Bytes())
+			return
+		}
+
 		w.Write([]byte{'['})
 		for i := 0; i < v.Len(); i++ {
 			if i > 0 {
@@ -57,7 +99,34 @@ func stringifyValue(w io.Writer, val reflect.Value) {
 
 		w.Write([]byte{']'})
 		return
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+
+		w.Write([]byte("map["))
+		for i, k := range keys {
+			if i > 0 {
+				w.Write([]byte{' '})
+			}
+
+			fmt.Fprint(w, k)
+			w.Write([]byte{':'})
+			mv := v.MapIndex(k)
+			if k.Kind() == reflect.String && isSecret(k.String()) && !isZero(mv) {
+				w.Write([]byte(redacted))
+				continue
+			}
+			stringifyValue(w, mv)
+		}
+		w.Write([]byte{']'})
 	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(PulpTime{}) && v.CanInterface() {
+			fmt.Fprintf(w, `"%s"`, v.Interface())
+			return
+		}
+
 		if v.Type().Name() != "" {
 			w.Write([]byte(v.Type().String()))
 		}
@@ -66,12 +135,18 @@ func stringifyValue(w io.Writer, val reflect.Value) {
 
 		var sep bool
 		for i := 0; i < v.NumField(); i++ {
-			fv := v.Field(i)
-			if fv.Kind() == reflect.Ptr && fv.IsNil() {
+			f := v.Type().Field(i)
+			if f.PkgPath != "" {
+				// unexported, like the client of a service
 				continue
 			}
-			if fv.Kind() == reflect.Slice && fv.IsNil() {
-				continue
+
+			fv := v.Field(i)
+			switch fv.Kind() {
+			case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+				if fv.IsNil() {
+					continue
+				}
 			}
 
 			if sep {
@@ -80,8 +155,12 @@ func stringifyValue(w io.Writer, val reflect.Value) {
 				sep = true
 			}
 
-			w.Write([]byte(v.Type().Field(i).Name))
+			w.Write([]byte(f.Name))
 			w.Write([]byte{':'})
+			if isSecret(f.Name) && !isZero(fv) {
+				w.Write([]byte(redacted))
+				continue
+			}
 			stringifyValue(w, fv)
 		}
 
@@ -0,0 +1,84 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"fmt"
+)
+
+// TaskGroupSummary counts the tasks of a group by state.
+type TaskGroupSummary struct {
+	Waiting   int `json:"waiting"`
+	Accepted  int `json:"accepted"`
+	Running   int `json:"running"`
+	Suspended int `json:"suspended"`
+	Finished  int `json:"finished"`
+	Error     int `json:"error"`
+	Canceled  int `json:"canceled"`
+	Skipped   int `json:"skipped"`
+	Total     int `json:"total"`
+}
+
+func (s TaskGroupSummary) String() string {
+	return Stringify(s)
+}
+
+// Done reports whether all the tasks of the group reached a final state.
+func (s *TaskGroupSummary) Done() bool {
+	return s.Finished+s.Error+s.Canceled+s.Skipped >= s.Total
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/task_groups.html
+func (s *TasksService) GetTaskGroup(group string) (*TaskGroupSummary, *Response, error) {
+	u := fmt.Sprintf("task_groups/%s/state-summary/", group)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g := new(TaskGroupSummary)
+	resp, err := s.client.Do(req, g)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return g, resp, err
+}
+
+// WaitForGroup polls the summary of the task group until all its tasks
+// reached a final state. An error is returned if any of the tasks failed.
+func (s *TasksService) WaitForGroup(group string, opt *PollOptions) (*TaskGroupSummary, error) {
+	var g *TaskGroupSummary
+	err := s.client.poll(opt, func() (done bool, err error) {
+		if g, _, err = s.GetTaskGroup(group); err != nil {
+			return false, err
+		}
+		return g.Done(), nil
+	})
+
+	switch {
+	case err == ErrTimeout:
+		return g, fmt.Errorf("%w waiting for task group %s", ErrTimeout, group)
+	case err != nil:
+		return nil, err
+	case g.Error > 0:
+		return g, fmt.Errorf("%w: %d of %d tasks of group %s", ErrTaskFailed, g.Error, g.Total, group)
+	}
+	return g, nil
+}
@@ -17,47 +17,119 @@
 package pulp
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
+	"time"
 )
 
 type TasksService struct {
 	client *Client
 }
 
+// states of a task
+const (
+	TaskWaiting   = "waiting"
+	TaskAccepted  = "accepted"
+	TaskRunning   = "running"
+	TaskSuspended = "suspended"
+	TaskFinished  = "finished"
+	TaskError     = "error"
+	TaskCanceled  = "canceled"
+	TaskSkipped   = "skipped"
+)
+
 // included in task
 type Task struct {
-	Id             string `json:"task_id"`
-	StartTime      string `json:"start_time"`
-	FinishTime     string `json:"finish_time"`
-	State          string `json:"state"`
-	Error          *Error `json:"error"`
-	ProgressReport struct {
-
-		// yum importer
-		YumImporter *Importer `json:"yum_importer"`
-
-		// docker importer
-		DockerImporter *Importer `json:"docker_importer"`
-	} `json:"progress_report"`
+	Id             string         `json:"task_id"`
+	TaskType       string         `json:"task_type"`
+	GroupId        string         `json:"group_id"`
+	Tags           []string       `json:"tags"`
+	StartTime      PulpTime       `json:"start_time"`
+	FinishTime     PulpTime       `json:"finish_time"`
+	State          string         `json:"state"`
+	Error          *Error         `json:"error"`
+	ProgressReport ProgressReport `json:"progress_report"`
 
 	Result struct {
 		Details struct {
-			Content *Content `json:"content"`
+			Content *ProgressSection `json:"content"`
 		} `json:"details"`
 	} `json:"result"`
+
+	// the undecoded result, e.g. the sync report of a sync task
+	RawResult json.RawMessage `json:"-"`
+
+	// the fields unknown to the client, encoded again with the task
+	ExtraFields map[string]json.RawMessage `json:"-"`
+}
+
+func (t *Task) UnmarshalJSON(data []byte) error {
+	type task Task
+	if err := json.Unmarshal(data, (*task)(t)); err != nil {
+		return err
+	}
+
+	var raw struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	t.RawResult = raw.Result
+
+	var err error
+	t.ExtraFields, err = extraFields(data, t)
+	return err
+}
+
+func (t Task) MarshalJSON() ([]byte, error) {
+	type task Task
+	data, err := marshalWithExtra(task(t), t.ExtraFields)
+	if err != nil || len(t.RawResult) == 0 {
+		return data, err
+	}
+
+	// the result is only partly decoded
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["result"] = t.RawResult
+	return json.Marshal(fields)
 }
 
 func (t *Task) String() string {
 	return Stringify(t)
 }
 
-func (t *Task) Importer() (importer string) {
-	if t.ProgressReport.YumImporter != nil {
-		importer = "yum"
+// Finished reports whether the task reached a final state.
+func (t *Task) Finished() bool {
+	switch t.State {
+	case TaskFinished, TaskError, TaskCanceled, TaskSkipped:
+		return true
+	}
+	return false
+}
+
+// Duration is the run time of the task, up to now if it is still running.
+func (t *Task) Duration() time.Duration {
+	if t.StartTime.IsZero() {
+		return 0
+	}
+	if t.FinishTime.IsZero() {
+		return time.Since(t.StartTime.Time)
 	}
+	return t.FinishTime.Sub(t.StartTime.Time)
+}
 
-	if t.ProgressReport.DockerImporter != nil {
-		importer = "docker"
+// Importer returns the type of the importer reporting progress without the
+// _importer suffix, e.g. yum or puppet.
+func (t *Task) Importer() (importer string) {
+	for _, importerType := range t.ProgressReport.ImporterTypes() {
+		importer = strings.TrimSuffix(importerType, "_importer")
 	}
 	return
 }
@@ -77,6 +149,102 @@ func (s *TasksService) ListTasks() ([]*Task, *Response, error) {
 	return t, resp, err
 }
 
+// TaskSearchCriteria are the typed filters supported by SearchTasks.
+// Zero values are not included in the search.
+type TaskSearchCriteria struct {
+	States        []string
+	Tags          []string // e.g. RepositoryTag("my-repo")
+	StartedAfter  time.Time
+	StartedBefore time.Time
+	Sort          []SortField
+	Limit         int
+	Skip          int
+}
+
+// tags used by pulp to mark the resources and actions of a task
+func RepositoryTag(repository string) string {
+	return "pulp:repository:" + repository
+}
+
+func ActionTag(action string) string {
+	return "pulp:action:" + action
+}
+
+func DistributorTag(distributor string) string {
+	return "pulp:repository_distributor:" + distributor
+}
+
+// HasTag reports whether the task is tagged with tag.
+func (t *Task) HasTag(tag string) bool {
+	for _, tt := range t.Tags {
+		if tt == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *TaskSearchCriteria) Criteria() *Criteria {
+	cr := NewCriteria()
+
+	if len(c.States) > 0 {
+		cr.Where(In("state", Strings(c.States)...))
+	}
+
+	if len(c.Tags) > 0 {
+		cr.Where(All("tags", Strings(c.Tags)...))
+	}
+
+	if !c.StartedAfter.IsZero() {
+		cr.Where(Gte("start_time", criteriaTime(c.StartedAfter)))
+	}
+	if !c.StartedBefore.IsZero() {
+		cr.Where(Lte("start_time", criteriaTime(c.StartedBefore)))
+	}
+
+	cr.Sort = c.Sort
+	cr.Limit = c.Limit
+	cr.Skip = c.Skip
+	return cr
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/tasks.html#searching-for-tasks
+func (s *TasksService) SearchTasks(criteria *TaskSearchCriteria) ([]*Task, *Response, error) {
+	if criteria == nil {
+		criteria = &TaskSearchCriteria{}
+	}
+	opt := &searchRequest{Criteria: criteria.Criteria()}
+
+	req, err := s.client.NewRequestWithQuery("POST", "tasks/search/", nil, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var t []*Task
+	resp, err := s.client.Do(req, &t)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return t, resp, err
+}
+
+// pendingTasks returns a call report of the tasks with all the tags which
+// are waiting or running, nil if there are none.
+func (s *TasksService) pendingTasks(tags []string) (*CallReport, *Response, error) {
+	tasks, resp, err := s.SearchTasks(&TaskSearchCriteria{States: pendingTaskStates, Tags: tags})
+	if err != nil || len(tasks) == 0 {
+		return nil, resp, err
+	}
+
+	cr := new(CallReport)
+	for _, t := range tasks {
+		cr.SpawnedTasks = append(cr.SpawnedTasks, SpawnedTask{Href: fmt.Sprintf("%stasks/%s/", s.client.apiURL().Path, t.Id), TaskId: t.Id})
+	}
+	return cr, resp, nil
+}
+
 func (s *TasksService) GetTask(task string) (*Task, *Response, error) {
 	u := fmt.Sprintf("tasks/%s/", task)
 
@@ -93,3 +261,148 @@ func (s *TasksService) GetTask(task string) (*Task, *Response, error) {
 
 	return t, resp, err
 }
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/tasks.html#cancelling-a-task
+func (s *TasksService) CancelTask(task string) (*Response, error) {
+	u := fmt.Sprintf("tasks/%s/", task)
+
+	req, err := s.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
+
+type PurgeTasksOptions struct {
+	States []string `url:"state,omitempty" json:"state,omitempty"`
+}
+
+// purge the task history of tasks in a completed state (finished, error,
+// skipped or canceled). All completed states are purged if none are given.
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/tasks.html#purging-tasks
+func (s *TasksService) PurgeCompletedTasks(states []string) (*Response, error) {
+	if len(states) == 0 {
+		states = []string{"finished", "error", "skipped", "canceled"}
+	}
+	opt := &PurgeTasksOptions{States: states}
+
+	req, err := s.client.NewRequest("DELETE", "tasks/", opt)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
+
+type PollOptions struct {
+	// delay between two polls, defaults to 1 second
+	Interval time.Duration
+
+	// gives up waiting after Timeout, 0 waits forever
+	Timeout time.Duration
+}
+
+// WaitForTask polls the task until it reaches a final state. An error is
+// returned if the task fails.
+func (s *TasksService) WaitForTask(task string, opt *PollOptions) (*Task, error) {
+	return s.waitForTask(task, opt, nil)
+}
+
+// WaitForTaskProgress waits for the task like WaitForTask, and sends its
+// progress report on the channel each time it changes. The channel is
+// closed when the wait returns; a slow receiver slows down the polling.
+func (s *TasksService) WaitForTaskProgress(task string, opt *PollOptions, progress chan<- ProgressReport) (*Task, error) {
+	defer close(progress)
+
+	var last *ProgressReport
+	return s.waitForTask(task, opt, func(t *Task) {
+		if last != nil && reflect.DeepEqual(*last, t.ProgressReport) {
+			return
+		}
+		last = &t.ProgressReport
+		progress <- t.ProgressReport
+	})
+}
+
+// onStateChange calls fn each time the state of the polled task changes.
+func onStateChange(fn func(*Task)) func(*Task) {
+	state := ""
+	return func(t *Task) {
+		if t.State != state {
+			state = t.State
+			fn(t)
+		}
+	}
+}
+
+// waitForTask calls onPoll with the task after each poll.
+func (s *TasksService) waitForTask(task string, opt *PollOptions, onPoll func(*Task)) (*Task, error) {
+	s.client.addInFlightTasks(1)
+	defer s.client.addInFlightTasks(-1)
+
+	var t *Task
+	err := s.client.poll(opt, func() (done bool, err error) {
+		if t, _, err = s.GetTask(task); err != nil {
+			return false, err
+		}
+
+		if onPoll != nil {
+			onPoll(t)
+		}
+		return t.Finished(), nil
+	})
+
+	switch {
+	case err == ErrTimeout:
+		return t, fmt.Errorf("%w waiting for task %s", ErrTimeout, task)
+	case err != nil:
+		return nil, err
+	case t.State == TaskError:
+		return t, fmt.Errorf("%w: task %s: %v", ErrTaskFailed, task, t.Error)
+	}
+	return t, nil
+}
+
+// poll calls fn every opt.Interval until it is done or fails. It returns
+// ErrTimeout itself, for the caller to wrap, when opt.Timeout expires
+// before, and the error of the context of the client when it is done.
+func (c *Client) poll(opt *PollOptions, fn func() (done bool, err error)) error {
+	interval := time.Second
+	var deadline time.Time
+	if opt != nil {
+		if opt.Interval > 0 {
+			interval = opt.Interval
+		}
+		if opt.Timeout > 0 {
+			deadline = time.Now().Add(opt.Timeout)
+		}
+	}
+
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	for {
+		done, err := fn()
+		if done || err != nil {
+			return err
+		}
+
+		if !deadline.IsZero() && time.Now().Add(interval).After(deadline) {
+			return ErrTimeout
+		}
+
+		t := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return timeoutError(ctx.Err())
+		case <-t.C:
+		}
+	}
+}
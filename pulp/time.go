@@ -0,0 +1,84 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// PulpTime decodes the ISO8601 timestamps returned by pulp, with or without
+// timezone and fractional seconds. Timestamps without timezone are UTC. A
+// null timestamp decodes to the zero time.
+type PulpTime struct {
+	time.Time
+}
+
+var pulpTimeFormats = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+}
+
+func ParsePulpTime(s string) (PulpTime, error) {
+	for _, f := range pulpTimeFormats {
+		if t, err := time.ParseInLocation(f, s, time.UTC); err == nil {
+			return PulpTime{t}, nil
+		}
+	}
+	return PulpTime{}, fmt.Errorf("invalid pulp time %q", s)
+}
+
+func (t *PulpTime) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		t.Time = time.Time{}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		t.Time = time.Time{}
+		return nil
+	}
+
+	pt, err := ParsePulpTime(s)
+	if err != nil {
+		return err
+	}
+	*t = pt
+	return nil
+}
+
+func (t PulpTime) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.UTC().Format(time.RFC3339Nano))
+}
+
+func (t PulpTime) String() string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,180 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net/http"
+)
+
+// LoadClientCertificate reads a PEM encoded certificate and key pair. Pulp
+// consumer certificates hold both in a single file, in which case keyFile
+// can be left empty.
+func LoadClientCertificate(certFile string, keyFile string) (tls.Certificate, error) {
+	if keyFile == "" {
+		keyFile = certFile
+	}
+	return tls.LoadX509KeyPair(certFile, keyFile)
+}
+
+// NewClientCertTransport builds a transport presenting the given client
+// certificates to the pulp server. Apart from the tls config it is the
+// same as DefaultTransport.
+func NewClientCertTransport(InsecureSkipVerify bool, certs ...tls.Certificate) *http.Transport {
+	t := DefaultTransport()
+	t.TLSClientConfig = &tls.Config{
+		InsecureSkipVerify: InsecureSkipVerify,
+		Certificates:       certs,
+	}
+	return t
+}
+
+// WithClientCertificate authenticates the client with a SSL client
+// certificate, the standard pulp consumer authentication. Leave the user
+// empty when creating the client to not send basic auth credentials.
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/authentication.html
+func WithClientCertificate(certFile string, keyFile string) ClientOption {
+	return func(c *Client) error {
+		cert, err := LoadClientCertificate(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+
+		return c.updateTransport(func(t *http.Transport) error {
+			ssl := tlsConfig(t)
+			ssl.Certificates = append(ssl.Certificates[:len(ssl.Certificates):len(ssl.Certificates)], cert)
+			return nil
+		})
+	}
+}
+
+// WithCACert trusts the CA certificates, given as PEM or as the path of a
+// PEM bundle file, to verify the server certificate, e.g. of a pulp server
+// with a self-signed certificate.
+func WithCACert(caCert string) ClientOption {
+	return func(c *Client) error {
+		pemCerts, err := ReadPEM(caCert)
+		if err != nil {
+			return err
+		}
+		return c.AddCACertificates([]byte(pemCerts))
+	}
+}
+
+// updateTransport applies fn to a clone of the transport of the client and
+// switches the client to the clone, so that neither requests in flight nor
+// clients sharing the transport see a half-applied change.
+func (c *Client) updateTransport(fn func(t *http.Transport) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.transport == nil {
+		return errors.New("pulp: the http client transport is not a *http.Transport")
+	}
+
+	// Clone also clones the tls config
+	old, t := c.transport, c.transport.Clone()
+	if err := fn(t); err != nil {
+		return err
+	}
+
+	c.transport = t
+	c.rebuild()
+	old.CloseIdleConnections()
+	return nil
+}
+
+// tlsConfig returns the tls config of the transport.
+func tlsConfig(t *http.Transport) *tls.Config {
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	return t.TLSClientConfig
+}
+
+// SetSSL switches the scheme of the client between https and http.
+func (c *Client) SetSSL(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.DisableSsl = !enabled
+	if c.baseURL != nil {
+		u := *c.baseURL
+		u.Scheme = "https"
+		if !enabled {
+			u.Scheme = "http"
+		}
+		c.baseURL = &u
+	}
+}
+
+// DisableSSLVerification disables the verification of the server
+// certificate, for servers with a self-signed certificate.
+func (c *Client) DisableSSLVerification() error {
+	err := c.updateTransport(func(t *http.Transport) error {
+		tlsConfig(t).InsecureSkipVerify = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.InsecureSkipVerify = true
+	c.mu.Unlock()
+	return nil
+}
+
+// AddCACertificates trusts the PEM encoded CA certificates in addition to
+// the system ones to verify the server certificate.
+func (c *Client) AddCACertificates(pemCerts []byte) error {
+	return c.updateTransport(func(t *http.Transport) error {
+		ssl := tlsConfig(t)
+
+		// the pool is shared with the clones of the tls config
+		var pool *x509.CertPool
+		if ssl.RootCAs != nil {
+			pool = ssl.RootCAs.Clone()
+		} else {
+			var err error
+			if pool, err = x509.SystemCertPool(); err != nil {
+				pool = x509.NewCertPool()
+			}
+		}
+
+		if !pool.AppendCertsFromPEM(pemCerts) {
+			return errors.New("pulp: no valid CA certificate found")
+		}
+
+		ssl.RootCAs = pool
+		return nil
+	})
+}
+
+// LoadCABundle trusts the CA certificates of a PEM bundle file.
+func (c *Client) LoadCABundle(file string) error {
+	pemCerts, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	return c.AddCACertificates(pemCerts)
+}
@@ -0,0 +1,525 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	RpmUnitType            = "rpm"
+	SrpmUnitType           = "srpm"
+	ErratumUnitType        = "erratum"
+	DockerImageUnitType    = "docker_image"
+	DockerManifestUnitType = "docker_manifest"
+	DockerTagUnitType      = "docker_tag"
+	DockerBlobUnitType     = "docker_blob"
+	PuppetModuleUnitType   = "puppet_module"
+	IsoUnitType            = "iso"
+)
+
+type UnitsService struct {
+	client *Client
+}
+
+// Unit is a content unit associated with a repository. Metadata is decoded
+// into the type specific struct registered for the UnitTypeId (e.g. a
+// *RpmUnit) or into a map for unknown types. RawMetadata keeps the
+// undecoded metadata.
+//
+// Created and Updated are the times the unit was associated with the
+// repository and the association last updated.
+type Unit struct {
+	Id          string          `json:"id"`
+	UnitId      string          `json:"unit_id"`
+	UnitTypeId  string          `json:"unit_type_id"`
+	RepoId      string          `json:"repo_id"`
+	Created     PulpTime        `json:"created"`
+	Updated     PulpTime        `json:"updated"`
+	OwnerType   string          `json:"owner_type"`
+	OwnerId     string          `json:"owner_id"`
+	Metadata    interface{}     `json:"-"`
+	RawMetadata json.RawMessage `json:"metadata"`
+
+	// the fields unknown to the client, encoded again with the unit
+	ExtraFields map[string]json.RawMessage `json:"-"`
+}
+
+func (u Unit) String() string {
+	return Stringify(u)
+}
+
+func (u *Unit) UnmarshalJSON(data []byte) error {
+	type unit Unit
+	if err := json.Unmarshal(data, (*unit)(u)); err != nil {
+		return err
+	}
+
+	var err error
+	if u.ExtraFields, err = extraFields(data, u); err != nil {
+		return err
+	}
+
+	u.Metadata, err = DecodeUnitMetadata(u.UnitTypeId, u.RawMetadata)
+	return err
+}
+
+func (u Unit) MarshalJSON() ([]byte, error) {
+	type unit Unit
+	return marshalWithExtra(unit(u), u.ExtraFields)
+}
+
+func (u *Unit) Rpm() *RpmUnit {
+	m, _ := u.Metadata.(*RpmUnit)
+	return m
+}
+
+func (u *Unit) Erratum() *ErratumUnit {
+	m, _ := u.Metadata.(*ErratumUnit)
+	return m
+}
+
+func (u *Unit) DockerImage() *DockerImageUnit {
+	m, _ := u.Metadata.(*DockerImageUnit)
+	return m
+}
+
+func (u *Unit) DockerManifest() *DockerManifestUnit {
+	m, _ := u.Metadata.(*DockerManifestUnit)
+	return m
+}
+
+func (u *Unit) DockerTag() *DockerTagUnit {
+	m, _ := u.Metadata.(*DockerTagUnit)
+	return m
+}
+
+func (u *Unit) DockerBlob() *DockerBlobUnit {
+	m, _ := u.Metadata.(*DockerBlobUnit)
+	return m
+}
+
+func (u *Unit) PuppetModule() *PuppetModuleUnit {
+	m, _ := u.Metadata.(*PuppetModuleUnit)
+	return m
+}
+
+func (u *Unit) Iso() *IsoUnit {
+	m, _ := u.Metadata.(*IsoUnit)
+	return m
+}
+
+var unitMetadataTypes = map[string]func() interface{}{
+	RpmUnitType:            func() interface{} { return new(RpmUnit) },
+	SrpmUnitType:           func() interface{} { return new(RpmUnit) },
+	ErratumUnitType:        func() interface{} { return new(ErratumUnit) },
+	DockerImageUnitType:    func() interface{} { return new(DockerImageUnit) },
+	DockerManifestUnitType: func() interface{} { return new(DockerManifestUnit) },
+	DockerTagUnitType:      func() interface{} { return new(DockerTagUnit) },
+	DockerBlobUnitType:     func() interface{} { return new(DockerBlobUnit) },
+	PuppetModuleUnitType:   func() interface{} { return new(PuppetModuleUnit) },
+	IsoUnitType:            func() interface{} { return new(IsoUnit) },
+}
+
+// RegisterUnitType registers the metadata struct used to decode the units
+// of a content type. It is not safe for concurrent use, call it from init.
+func RegisterUnitType(typeId string, newMetadata func() interface{}) {
+	unitMetadataTypes[typeId] = newMetadata
+}
+
+// DecodeUnitMetadata decodes the metadata of a unit of the given type.
+func DecodeUnitMetadata(typeId string, data json.RawMessage) (interface{}, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+
+	var m interface{}
+	if f, ok := unitMetadataTypes[typeId]; ok {
+		m = f()
+	} else {
+		m = &map[string]interface{}{}
+	}
+
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("decoding %s unit metadata: %v", typeId, err)
+	}
+
+	if mp, ok := m.(*map[string]interface{}); ok {
+		return *mp, nil
+	}
+	return m, nil
+}
+
+// UnitMetadata holds the fields shared by the metadata of all unit types.
+type UnitMetadata struct {
+	Id            string `json:"_id"`
+	ContentTypeId string `json:"_content_type_id"`
+	StoragePath   string `json:"_storage_path"`
+}
+
+type RpmDependency struct {
+	Name    string `json:"name"`
+	Flags   string `json:"flags"`
+	Epoch   string `json:"epoch"`
+	Version string `json:"version"`
+	Release string `json:"release"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/plugins/pulp_rpm/tech-reference/rpm.html#rpm
+type RpmUnit struct {
+	UnitMetadata
+	Name         string           `json:"name"`
+	Epoch        string           `json:"epoch"`
+	Version      string           `json:"version"`
+	Release      string           `json:"release"`
+	Arch         string           `json:"arch"`
+	Checksum     string           `json:"checksum"`
+	ChecksumType string           `json:"checksumtype"`
+	Filename     string           `json:"filename"`
+	RelativePath string           `json:"relativepath"`
+	Summary      string           `json:"summary"`
+	Description  string           `json:"description"`
+	License      string           `json:"license"`
+	Vendor       string           `json:"vendor"`
+	BuildHost    string           `json:"buildhost"`
+	SourceRpm    string           `json:"sourcerpm"`
+	Size         int64            `json:"size"`
+	Requires     []*RpmDependency `json:"requires"`
+	Provides     []*RpmDependency `json:"provides"`
+}
+
+type ErratumPackage struct {
+	Name     string   `json:"name"`
+	Epoch    string   `json:"epoch"`
+	Version  string   `json:"version"`
+	Release  string   `json:"release"`
+	Arch     string   `json:"arch"`
+	Filename string   `json:"filename"`
+	Src      string   `json:"src"`
+	Sum      []string `json:"sum"`
+}
+
+type ErratumPkgList struct {
+	Name     string            `json:"name"`
+	Short    string            `json:"short"`
+	Packages []*ErratumPackage `json:"packages"`
+}
+
+type ErratumReference struct {
+	Id    string `json:"id"`
+	Href  string `json:"href"`
+	Title string `json:"title"`
+	Type  string `json:"type"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/plugins/pulp_rpm/tech-reference/rpm.html#erratum
+type ErratumUnit struct {
+	UnitMetadata
+	ErratumId   string              `json:"id"`
+	Title       string              `json:"title"`
+	Type        string              `json:"type"`
+	Severity    string              `json:"severity"`
+	Status      string              `json:"status"`
+	Issued      string              `json:"issued"`
+	Updated     string              `json:"updated"`
+	Version     string              `json:"version"`
+	Release     string              `json:"release"`
+	From        string              `json:"from"`
+	Summary     string              `json:"summary"`
+	Description string              `json:"description"`
+	Solution    string              `json:"solution"`
+	Rights      string              `json:"rights"`
+	PkgList     []*ErratumPkgList   `json:"pkglist"`
+	References  []*ErratumReference `json:"references"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/plugins/pulp_docker/tech-reference/image.html
+type DockerImageUnit struct {
+	UnitMetadata
+	ImageId  string `json:"image_id"`
+	ParentId string `json:"parent_id"`
+	Size     int64  `json:"size"`
+}
+
+type DockerFsLayer struct {
+	BlobSum string `json:"blob_sum"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/plugins/pulp_docker/tech-reference/manifest.html
+type DockerManifestUnit struct {
+	UnitMetadata
+	Name          string           `json:"name"`
+	Tag           string           `json:"tag"`
+	Digest        string           `json:"digest"`
+	SchemaVersion int              `json:"schema_version"`
+	FsLayers      []*DockerFsLayer `json:"fs_layers"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/plugins/pulp_docker/tech-reference/tags.html
+type DockerTagUnit struct {
+	UnitMetadata
+	Name           string `json:"name"`
+	ManifestDigest string `json:"manifest_digest"`
+	RepoId         string `json:"repo_id"`
+	SchemaVersion  int    `json:"schema_version"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/plugins/pulp_docker/tech-reference/blob.html
+type DockerBlobUnit struct {
+	UnitMetadata
+	Digest string `json:"digest"`
+}
+
+type PuppetDependency struct {
+	Name               string `json:"name"`
+	VersionRequirement string `json:"version_requirement"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/plugins/pulp_puppet/tech-reference/puppet_module.html
+type PuppetModuleUnit struct {
+	UnitMetadata
+	Name         string              `json:"name"`
+	Author       string              `json:"author"`
+	Version      string              `json:"version"`
+	Summary      string              `json:"summary"`
+	Description  string              `json:"description"`
+	License      string              `json:"license"`
+	Source       string              `json:"source"`
+	ProjectPage  string              `json:"project_page"`
+	Checksum     string              `json:"checksum"`
+	ChecksumType string              `json:"checksum_type"`
+	TagList      []string            `json:"tag_list"`
+	Dependencies []*PuppetDependency `json:"dependencies"`
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/plugins/pulp_rpm/tech-reference/iso-plugins.html
+type IsoUnit struct {
+	UnitMetadata
+	Name     string `json:"name"`
+	Checksum string `json:"checksum"`
+	Size     int64  `json:"size"`
+}
+
+// ListUnitsOptions are the criteria of ListUnits, e.g. the rpm units named
+// kernel:
+//
+//	&pulp.ListUnitsOptions{
+//		TypeIds:     []string{pulp.RpmUnitType},
+//		UnitFilters: pulp.Eq("name", "kernel"),
+//	}
+//
+// CreatedAfter and CreatedBefore select the units associated with the
+// repository in a time range, AssociationSort sorts on the association
+// fields, e.g. {Field: "created", Direction: SortDescending}. Limit and Skip
+// page through the results.
+type ListUnitsOptions struct {
+	TypeIds            []string    `json:"type_ids,omitempty"`
+	Fields             []string    `json:"fields,omitempty"`
+	UnitFilters        Filter      `json:"-"`
+	AssociationFilters Filter      `json:"-"`
+	CreatedAfter       *time.Time  `json:"-"`
+	CreatedBefore      *time.Time  `json:"-"`
+	UnitSort           []SortField `json:"-"`
+	AssociationSort    []SortField `json:"-"`
+	Limit              int         `json:"-"`
+	Skip               int         `json:"-"`
+}
+
+// criteria returns the unit criteria of the options.
+func (opt *ListUnitsOptions) criteria() *UnitCriteria {
+	c := NewUnitCriteria()
+	if opt == nil {
+		return c
+	}
+
+	c.TypeIds = opt.TypeIds
+	if len(opt.Fields) > 0 {
+		c.SelectUnitFields(opt.Fields...)
+	}
+
+	if len(opt.UnitFilters) > 0 {
+		c.WhereUnit(opt.UnitFilters)
+	}
+	if len(opt.AssociationFilters) > 0 {
+		c.WhereAssociation(opt.AssociationFilters)
+	}
+
+	var after, before time.Time
+	if opt.CreatedAfter != nil {
+		after = *opt.CreatedAfter
+	}
+	if opt.CreatedBefore != nil {
+		before = *opt.CreatedBefore
+	}
+	c.CreatedBetween(after, before)
+
+	for _, f := range opt.UnitSort {
+		c.OrderUnitsBy(f.Field, f.Direction)
+	}
+	for _, f := range opt.AssociationSort {
+		c.OrderAssociationsBy(f.Field, f.Direction)
+	}
+
+	return c.SetLimit(opt.Limit).SetSkip(opt.Skip)
+}
+
+type unitSearchRequest struct {
+	Criteria *UnitCriteria `json:"criteria"`
+}
+
+// ListUnits lists the units associated with the repository matching the
+// options. Fields limits the returned unit metadata to the given fields.
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/content/retrieval.html#search-for-units
+func (s *UnitsService) ListUnits(repository string, opt *ListUnitsOptions) ([]*Unit, *Response, error) {
+	u := fmt.Sprintf("repositories/%s/search/units/", repository)
+
+	c := opt.criteria()
+
+	req, err := s.client.NewRequestWithQuery("POST", u, nil, &unitSearchRequest{Criteria: c})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var units []*Unit
+	resp, err := s.client.Do(req, &units)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return units, resp, err
+}
+
+// SearchUnits searches the units associated with the repository.
+func (s *UnitsService) SearchUnits(repository string, criteria *UnitCriteria) ([]*Unit, *Response, error) {
+	u := fmt.Sprintf("repositories/%s/search/units/", repository)
+
+	if criteria == nil {
+		criteria = NewUnitCriteria()
+	}
+
+	req, err := s.client.NewRequestWithQuery("POST", u, nil, &unitSearchRequest{Criteria: criteria})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var units []*Unit
+	resp, err := s.client.Do(req, &units)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return units, resp, err
+}
+
+type copyUnitsRequest struct {
+	SourceRepoId   string        `json:"source_repo_id"`
+	Criteria       *UnitCriteria `json:"criteria,omitempty"`
+	OverrideConfig interface{}   `json:"override_config,omitempty"`
+}
+
+// CopyUnits associates the units of the source repository matching the
+// criteria with the destination repository. The importer of the destination
+// repository can be configured for this copy with overrideConfig, e.g.
+// {"recursive": true} to also copy the dependencies of the units.
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/content/associate.html#copying-units-between-repositories
+func (s *UnitsService) CopyUnits(source string, destination string, criteria *UnitCriteria, overrideConfig interface{}) (*CallReport, *Response, error) {
+	u := fmt.Sprintf("repositories/%s/actions/associate/", destination)
+
+	opt := &copyUnitsRequest{
+		SourceRepoId:   source,
+		Criteria:       criteria,
+		OverrideConfig: overrideConfig,
+	}
+
+	req, err := s.client.NewRequest("POST", u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cr := new(CallReport)
+	resp, err := s.client.Do(req, cr)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return cr, resp, err
+}
+
+// UnassociateUnits removes the units matching the criteria from the
+// repository.
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/content/associate.html#unassociating-content-units-from-a-repository
+func (s *UnitsService) UnassociateUnits(repository string, criteria *UnitCriteria) (*CallReport, *Response, error) {
+	u := fmt.Sprintf("repositories/%s/actions/unassociate/", repository)
+
+	req, err := s.client.NewRequest("POST", u, &unitSearchRequest{Criteria: criteria})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cr := new(CallReport)
+	resp, err := s.client.Do(req, cr)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return cr, resp, err
+}
+
+// UploadId is nil for units without a file, like docker tags.
+type ImportUploadOptions struct {
+	UploadId     *string     `json:"upload_id"`
+	UnitTypeId   string      `json:"unit_type_id"`
+	UnitKey      interface{} `json:"unit_key"`
+	UnitMetadata interface{} `json:"unit_metadata,omitempty"`
+}
+
+// ImportUpload imports an uploaded unit into the repository.
+//
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/content/upload.html#import-into-a-repository
+func (s *UnitsService) ImportUpload(repository string, opt *ImportUploadOptions) (*CallReport, *Response, error) {
+	u := fmt.Sprintf("repositories/%s/actions/import_upload/", repository)
+
+	req, err := s.client.NewRequest("POST", u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cr := new(CallReport)
+	resp, err := s.client.Do(req, cr)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return cr, resp, err
+}
@@ -0,0 +1,267 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// default size of the chunks sent by Upload
+const DefaultUploadChunkSize = 1 << 20
+
+// UploadsService handles the upload requests, which hold the bits of a file
+// until they are imported into a repository with UnitsService.ImportUpload.
+type UploadsService struct {
+	client *Client
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/content/upload.html
+type Upload struct {
+	UploadId string `json:"upload_id"`
+	Href     string `json:"_href"`
+}
+
+func (u Upload) String() string {
+	return Stringify(u)
+}
+
+func (s *UploadsService) CreateUpload() (*Upload, *Response, error) {
+	req, err := s.client.NewRequest("POST", "content/uploads/", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	u := new(Upload)
+	resp, err := s.client.Do(req, u)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return u, resp, err
+}
+
+func (s *UploadsService) ListUploads() ([]string, *Response, error) {
+	req, err := s.client.NewRequest("GET", "content/uploads/", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var u struct {
+		UploadIds []string `json:"upload_ids"`
+	}
+	resp, err := s.client.Do(req, &u)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return u.UploadIds, resp, err
+}
+
+// UploadBits sends a chunk of the file, starting at offset.
+func (s *UploadsService) UploadBits(upload string, offset int64, data []byte) (*Response, error) {
+	u := fmt.Sprintf("content/uploads/%s/%d/", upload, offset)
+
+	req, err := s.client.NewRequestWithBody("PUT", u, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	return s.client.Do(req, nil)
+}
+
+func (s *UploadsService) DeleteUpload(upload string) (*Response, error) {
+	u := fmt.Sprintf("content/uploads/%s/", upload)
+
+	req, err := s.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
+
+// Upload creates an upload request and sends the content of r in chunks of
+// chunkSize bytes, DefaultUploadChunkSize if 0. It returns the upload id
+// and the number of bytes sent. The upload request is deleted if sending
+// fails.
+func (s *UploadsService) Upload(r io.Reader, chunkSize int) (string, int64, error) {
+	if chunkSize <= 0 {
+		chunkSize = DefaultUploadChunkSize
+	}
+
+	upload, _, err := s.CreateUpload()
+	if err != nil {
+		return "", 0, err
+	}
+
+	var offset int64
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			// the chunk is copied, as a retried request may replay it
+			chunk := append([]byte(nil), buf[:n]...)
+			if _, uerr := s.UploadBits(upload.UploadId, offset, chunk); uerr != nil {
+				s.DeleteUpload(upload.UploadId)
+				return "", offset, uerr
+			}
+			offset += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			s.DeleteUpload(upload.UploadId)
+			return "", offset, err
+		}
+	}
+
+	return upload.UploadId, offset, nil
+}
+
+// UploadReport is the outcome of the upload of a file.
+type UploadReport struct {
+	File       string
+	UnitTypeId string
+	Size       int64
+	Checksums  map[string]string
+	Tasks      []*Task
+	Err        error
+}
+
+func (r UploadReport) String() string {
+	return Stringify(r)
+}
+
+// UploadProgressFunc is called when the upload of a file is done, with the
+// error if it failed. It is called from several goroutines.
+type UploadProgressFunc func(file string, err error)
+
+type UploadDirectoryOptions struct {
+	// publishes the repository with all its distributors after the uploads,
+	// unless one failed
+	Publish bool
+
+	Progress UploadProgressFunc
+
+	// polls the import and publish tasks
+	Poll *PollOptions
+}
+
+// UploadDirectory uploads the rpms, srpms and isos found in dir and its
+// subdirectories into the repository, running at most concurrency uploads
+// at a time. Rpms are imported with an empty unit key, pulp reads it from
+// the rpm headers. The reports are returned in the order of the files; the
+// error is a *BulkError listing the failed uploads.
+func (s *UploadsService) UploadDirectory(repository string, dir string, concurrency int, opt *UploadDirectoryOptions) ([]*UploadReport, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if opt == nil {
+		opt = &UploadDirectoryOptions{}
+	}
+
+	var reports []*UploadReport
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if typeId := uploadUnitType(path); typeId != "" && info.Mode().IsRegular() {
+			reports = append(reports, &UploadReport{File: path, UnitTypeId: typeId})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for _, r := range reports {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(r *UploadReport) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			r.Tasks, r.Err = s.uploadFile(repository, r, opt.Poll)
+			if opt.Progress != nil {
+				opt.Progress(r.File, r.Err)
+			}
+		}(r)
+	}
+	wg.Wait()
+
+	errs := make(map[string]error)
+	for _, r := range reports {
+		if r.Err != nil {
+			errs[r.File] = r.Err
+		}
+	}
+	if len(errs) > 0 {
+		return reports, &BulkError{Errors: errs}
+	}
+
+	if opt.Publish {
+		if _, err := s.client.Repositories.publishAndWait(repository, nil, opt.Poll); err != nil {
+			return reports, err
+		}
+	}
+	return reports, nil
+}
+
+func (s *UploadsService) uploadFile(repository string, r *UploadReport, poll *PollOptions) ([]*Task, error) {
+	f, err := os.Open(r.File)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	name := filepath.Base(r.File)
+	return s.client.Units.uploadAndImport(repository, r.UnitTypeId, f, func(cr *ChecksumReader) interface{} {
+		r.Size, r.Checksums = cr.Size(), cr.Sums()
+		if r.UnitTypeId == IsoUnitType {
+			return map[string]interface{}{"name": name, "checksum": cr.Sum(ChecksumSha256), "size": r.Size}
+		}
+		return map[string]interface{}{}
+	}, poll)
+}
+
+// uploadUnitType returns the unit type of a file by its extension, or an
+// empty string for files which are not uploaded.
+func uploadUnitType(file string) string {
+	switch {
+	case strings.HasSuffix(file, ".src.rpm"):
+		return SrpmUnitType
+	case strings.HasSuffix(file, ".rpm"):
+		return RpmUnitType
+	case strings.HasSuffix(file, ".iso"):
+		return IsoUnitType
+	}
+	return ""
+}
@@ -0,0 +1,145 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"fmt"
+)
+
+type UsersService struct {
+	client *Client
+}
+
+type User struct {
+	Id    string   `json:"id"`
+	Login string   `json:"login"`
+	Name  string   `json:"name"`
+	Roles []string `json:"roles"`
+	Href  string   `json:"_href"`
+}
+
+func (u User) String() string {
+	return Stringify(u)
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/user/retrieval.html
+func (s *UsersService) ListUsers() ([]*User, *Response, error) {
+	req, err := s.client.NewRequest("GET", "users/", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var u []*User
+	resp, err := s.client.Do(req, &u)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return u, resp, err
+}
+
+func (s *UsersService) GetUser(login string) (*User, *Response, error) {
+	u := fmt.Sprintf("users/%s/", login)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	usr := new(User)
+	resp, err := s.client.Do(req, usr)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return usr, resp, err
+}
+
+type CreateUserOptions struct {
+	Login    string `json:"login"`
+	Password string `json:"password,omitempty"`
+	Name     string `json:"name,omitempty"`
+}
+
+func (c CreateUserOptions) String() string {
+	return Stringify(c)
+}
+
+// Pulp Api docs:
+// http://pulp.readthedocs.org/en/latest/dev-guide/integration/rest-api/user/cud.html
+func (s *UsersService) CreateUser(opt *CreateUserOptions) (*User, *Response, error) {
+	req, err := s.client.NewRequest("POST", "users/", opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	usr := new(User)
+	resp, err := s.client.Do(req, usr)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return usr, resp, err
+}
+
+// only the non nil fields are updated
+type UpdateUserOptions struct {
+	Password *string  `json:"password,omitempty"`
+	Name     *string  `json:"name,omitempty"`
+	Roles    []string `json:"roles,omitempty"`
+}
+
+func (u UpdateUserOptions) String() string {
+	return Stringify(u)
+}
+
+type updateUserRequest struct {
+	Delta *UpdateUserOptions `json:"delta"`
+}
+
+func (s *UsersService) UpdateUser(login string, opt *UpdateUserOptions) (*User, *Response, error) {
+	u := fmt.Sprintf("users/%s/", login)
+
+	req, err := s.client.NewRequest("PUT", u, &updateUserRequest{Delta: opt})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	usr := new(User)
+	resp, err := s.client.Do(req, usr)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return usr, resp, err
+}
+
+func (s *UsersService) SetPassword(login string, password string) (*User, *Response, error) {
+	return s.UpdateUser(login, &UpdateUserOptions{Password: String(password)})
+}
+
+func (s *UsersService) DeleteUser(login string) (*Response, error) {
+	u := fmt.Sprintf("users/%s/", login)
+
+	req, err := s.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
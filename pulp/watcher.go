@@ -0,0 +1,211 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp
+
+import (
+	"sync"
+	"time"
+)
+
+// TaskEvent reports a state transition of a watched task, or an error
+// polling it. PreviousState is empty the first time a task is seen.
+type TaskEvent struct {
+	TaskId        string
+	Task          *Task
+	PreviousState string
+	Err           error
+}
+
+type TaskWatcherOptions struct {
+	// delay between two polls of a task, defaults to 1 second
+	Interval time.Duration
+
+	// number of concurrent polls, defaults to 4
+	Workers int
+}
+
+// TaskWatcher polls many tasks with a pool of workers and delivers their
+// state transitions on a single channel. Tasks are no longer watched once
+// they reach a final state.
+//
+//	w := client.NewTaskWatcher(nil)
+//	defer w.Stop()
+//	w.Watch(ids...)
+//	for e := range w.Events() {
+//		...
+//	}
+type TaskWatcher struct {
+	client   *Client
+	interval time.Duration
+
+	add     chan []string
+	jobs    chan string
+	results chan *TaskEvent
+	events  chan *TaskEvent
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	workers  sync.WaitGroup
+}
+
+func (c *Client) NewTaskWatcher(opt *TaskWatcherOptions) *TaskWatcher {
+	w := &TaskWatcher{
+		client:   c,
+		interval: time.Second,
+		add:      make(chan []string),
+		jobs:     make(chan string),
+		results:  make(chan *TaskEvent),
+		events:   make(chan *TaskEvent),
+		stop:     make(chan struct{}),
+	}
+
+	workers := 4
+	if opt != nil {
+		if opt.Interval > 0 {
+			w.interval = opt.Interval
+		}
+		if opt.Workers > 0 {
+			workers = opt.Workers
+		}
+	}
+
+	w.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go w.worker()
+	}
+	go w.run()
+
+	return w
+}
+
+// Watch adds tasks to the watcher.
+func (w *TaskWatcher) Watch(tasks ...string) {
+	select {
+	case w.add <- tasks:
+	case <-w.stop:
+	}
+}
+
+// Events delivers the task events, it is closed when the watcher stops.
+func (w *TaskWatcher) Events() <-chan *TaskEvent {
+	return w.events
+}
+
+// Stop stops polling the tasks, pending events are dropped.
+func (w *TaskWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+}
+
+func (w *TaskWatcher) worker() {
+	defer w.workers.Done()
+
+	for {
+		select {
+		case id := <-w.jobs:
+			t, _, err := w.client.Tasks.GetTask(id)
+			select {
+			case w.results <- &TaskEvent{TaskId: id, Task: t, Err: err}:
+			case <-w.stop:
+				return
+			}
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+func (w *TaskWatcher) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	states := make(map[string]string)
+	polling := make(map[string]bool)
+	var queue []string
+	var pending []*TaskEvent
+
+	enqueue := func() {
+		for id := range states {
+			if !polling[id] {
+				polling[id] = true
+				queue = append(queue, id)
+			}
+		}
+	}
+
+	for {
+		// only offer a job or an event when there is one
+		var jobs chan string
+		var next string
+		if len(queue) > 0 {
+			jobs, next = w.jobs, queue[0]
+		}
+		var events chan *TaskEvent
+		var event *TaskEvent
+		if len(pending) > 0 {
+			events, event = w.events, pending[0]
+		}
+
+		select {
+		case ids := <-w.add:
+			for _, id := range ids {
+				if _, ok := states[id]; !ok {
+					states[id] = ""
+				}
+			}
+			enqueue()
+
+		case <-ticker.C:
+			enqueue()
+
+		case jobs <- next:
+			queue = queue[1:]
+
+		case e := <-w.results:
+			delete(polling, e.TaskId)
+			if _, ok := states[e.TaskId]; !ok {
+				continue
+			}
+
+			if e.Err != nil {
+				pending = append(pending, e)
+				if er, ok := e.Err.(*ErrorResponse); ok && er.IsNotFound() {
+					delete(states, e.TaskId)
+				}
+				continue
+			}
+
+			if prev := states[e.TaskId]; prev != e.Task.State {
+				e.PreviousState = prev
+				states[e.TaskId] = e.Task.State
+				pending = append(pending, e)
+			}
+			if e.Task.Finished() {
+				delete(states, e.TaskId)
+			}
+
+		case events <- event:
+			pending = pending[1:]
+
+		case <-w.stop:
+			w.workers.Wait()
+			close(w.events)
+			return
+		}
+	}
+}
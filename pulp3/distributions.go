@@ -0,0 +1,89 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp3
+
+import (
+	"github.com/msutter/go-pulp/pulp"
+)
+
+type DistributionsService struct {
+	client *Client
+}
+
+type Distribution struct {
+	Href         string `json:"pulp_href"`
+	Created      string `json:"pulp_created"`
+	Name         string `json:"name"`
+	BasePath     string `json:"base_path"`
+	BaseUrl      string `json:"base_url"`
+	ContentGuard string `json:"content_guard"`
+	Repository   string `json:"repository"`
+	Publication  string `json:"publication"`
+}
+
+func (d Distribution) String() string {
+	return pulp.Stringify(d)
+}
+
+type distributionList struct {
+	Count   int             `json:"count"`
+	Results []*Distribution `json:"results"`
+}
+
+// Pulp Api docs:
+// https://docs.pulpproject.org/pulpcore/restapi.html#tag/Distributions
+func (s *DistributionsService) ListDistributions(opt *ListOptions) ([]*Distribution, *pulp.Response, error) {
+	l := new(distributionList)
+	resp, err := s.client.get("distributions/", opt, l)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return l.Results, resp, err
+}
+
+func (s *DistributionsService) GetDistribution(href string) (*Distribution, *pulp.Response, error) {
+	d := new(Distribution)
+	resp, err := s.client.get(href, nil, d)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return d, resp, err
+}
+
+type DistributionOptions struct {
+	Name         string `json:"name,omitempty"`
+	BasePath     string `json:"base_path,omitempty"`
+	ContentGuard string `json:"content_guard,omitempty"`
+	Repository   string `json:"repository,omitempty"`
+	Publication  string `json:"publication,omitempty"`
+}
+
+// CreateDistribution creates a distribution of the given plugin type, e.g.
+// "rpm/rpm".
+func (s *DistributionsService) CreateDistribution(pluginType string, opt *DistributionOptions) (*AsyncOperationResponse, *pulp.Response, error) {
+	return s.client.async("POST", "distributions/"+pluginType+"/", opt)
+}
+
+func (s *DistributionsService) UpdateDistribution(href string, opt *DistributionOptions) (*AsyncOperationResponse, *pulp.Response, error) {
+	return s.client.async("PATCH", href, opt)
+}
+
+func (s *DistributionsService) DeleteDistribution(href string) (*AsyncOperationResponse, *pulp.Response, error) {
+	return s.client.async("DELETE", href, nil)
+}
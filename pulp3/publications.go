@@ -0,0 +1,80 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp3
+
+import (
+	"github.com/msutter/go-pulp/pulp"
+)
+
+type PublicationsService struct {
+	client *Client
+}
+
+type Publication struct {
+	Href              string `json:"pulp_href"`
+	Created           string `json:"pulp_created"`
+	Repository        string `json:"repository"`
+	RepositoryVersion string `json:"repository_version"`
+}
+
+func (p Publication) String() string {
+	return pulp.Stringify(p)
+}
+
+type publicationList struct {
+	Count   int            `json:"count"`
+	Results []*Publication `json:"results"`
+}
+
+// Pulp Api docs:
+// https://docs.pulpproject.org/pulpcore/restapi.html#tag/Publications
+func (s *PublicationsService) ListPublications(opt *ListOptions) ([]*Publication, *pulp.Response, error) {
+	l := new(publicationList)
+	resp, err := s.client.get("publications/", opt, l)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return l.Results, resp, err
+}
+
+func (s *PublicationsService) GetPublication(href string) (*Publication, *pulp.Response, error) {
+	p := new(Publication)
+	resp, err := s.client.get(href, nil, p)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return p, resp, err
+}
+
+// set either the repository, to publish its latest version, or a
+// repository version
+type PublicationOptions struct {
+	Repository        string `json:"repository,omitempty"`
+	RepositoryVersion string `json:"repository_version,omitempty"`
+}
+
+// CreatePublication publishes a repository version with the given plugin
+// type, e.g. "rpm/rpm".
+func (s *PublicationsService) CreatePublication(pluginType string, opt *PublicationOptions) (*AsyncOperationResponse, *pulp.Response, error) {
+	return s.client.async("POST", "publications/"+pluginType+"/", opt)
+}
+
+func (s *PublicationsService) DeletePublication(href string) (*pulp.Response, error) {
+	return s.client.send("DELETE", href, nil, nil)
+}
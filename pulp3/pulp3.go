@@ -0,0 +1,108 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+// Package pulp3 is a client for the Pulp 3 REST API. Resources are
+// identified by their href instead of an id; the typed resources are
+// created under a plugin specific path like "rpm/rpm" or "file/file".
+//
+// The client wraps a pulp.Client, so all its options (authentication,
+// retries, hooks, ...) apply.
+package pulp3
+
+import (
+	"strings"
+
+	"github.com/msutter/go-pulp/pulp"
+)
+
+const apiPath = "/pulp/api/v3/"
+
+type Client struct {
+	*pulp.Client
+
+	// Services used for talking to different parts of the Pulp 3 API.
+	Distributions *DistributionsService
+	Publications  *PublicationsService
+	Remotes       *RemotesService
+	Repositories  *RepositoriesService
+	Tasks         *TasksService
+}
+
+func NewClient(host string, options ...pulp.ClientOption) (*Client, error) {
+	pc, err := pulp.NewClient(host, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	u := pc.BaseURL()
+	u.Path = apiPath
+	if err := pc.SetBaseURL(u.String()); err != nil {
+		return nil, err
+	}
+
+	c := &Client{Client: pc}
+	c.Distributions = &DistributionsService{client: c}
+	c.Publications = &PublicationsService{client: c}
+	c.Remotes = &RemotesService{client: c}
+	c.Repositories = &RepositoriesService{client: c}
+	c.Tasks = &TasksService{client: c}
+
+	return c, nil
+}
+
+// ListOptions selects a page of a list.
+type ListOptions struct {
+	Limit  int `url:"limit,omitempty" json:"limit,omitempty"`
+	Offset int `url:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// AsyncOperationResponse is returned by the operations run in a task.
+type AsyncOperationResponse struct {
+	Task string `json:"task"`
+}
+
+// path converts an href to a path relative to the api root.
+func (c *Client) path(href string) string {
+	return strings.TrimPrefix(href, c.BaseURL().Path)
+}
+
+func (c *Client) get(href string, opt interface{}, v interface{}) (*pulp.Response, error) {
+	req, err := c.NewRequest("GET", c.path(href), opt)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Do(req, v)
+}
+
+func (c *Client) send(method string, href string, opt interface{}, v interface{}) (*pulp.Response, error) {
+	req, err := c.NewRequest(method, c.path(href), opt)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Do(req, v)
+}
+
+func (c *Client) async(method string, href string, opt interface{}) (*AsyncOperationResponse, *pulp.Response, error) {
+	a := new(AsyncOperationResponse)
+	resp, err := c.send(method, href, opt, a)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return a, resp, err
+}
@@ -0,0 +1,119 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp3
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/msutter/go-pulp/pulp"
+)
+
+func newTestClient(t *testing.T, h http.Handler) *Client {
+	server := httptest.NewServer(h)
+	t.Cleanup(server.Close)
+
+	c, err := NewClient(strings.TrimPrefix(server.URL, "http://"), pulp.WithoutSSL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestPath(t *testing.T) {
+	c := newTestClient(t, http.NotFoundHandler())
+
+	tests := []struct {
+		href string
+		want string
+	}{
+		{"tasks/", "tasks/"},
+		{"/pulp/api/v3/tasks/", "tasks/"},
+		{"/pulp/api/v3/repositories/rpm/rpm/0190/", "repositories/rpm/rpm/0190/"},
+	}
+
+	for _, tt := range tests {
+		if got := c.path(tt.href); got != tt.want {
+			t.Errorf("path(%q) = %q, want %q", tt.href, got, tt.want)
+		}
+	}
+}
+
+func TestSyncRepository(t *testing.T) {
+	const (
+		repo = "/pulp/api/v3/repositories/rpm/rpm/0190/"
+		task = "/pulp/api/v3/tasks/0191/"
+	)
+
+	var requests []string
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.Method + " " + r.URL.Path {
+		case "POST " + repo + "sync/":
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"task":"` + task + `"}`))
+		case "GET " + task:
+			w.Write([]byte(`{"pulp_href":"` + task + `","state":"canceling"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	a, _, err := c.Repositories.SyncRepository(repo, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Task != task {
+		t.Fatalf("SyncRepository() task = %q, want %q", a.Task, task)
+	}
+
+	tk, _, err := c.Tasks.GetTask(a.Task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tk.Finished() {
+		t.Errorf("task in state %q is finished", tk.State)
+	}
+
+	want := []string{"POST " + repo + "sync/", "GET " + task}
+	if strings.Join(requests, ", ") != strings.Join(want, ", ") {
+		t.Errorf("requests = %v, want %v", requests, want)
+	}
+}
+
+func TestTaskFinished(t *testing.T) {
+	tests := []struct {
+		state string
+		want  bool
+	}{
+		{TaskWaiting, false},
+		{TaskRunning, false},
+		{TaskCanceling, false},
+		{TaskCompleted, true},
+		{TaskFailed, true},
+		{TaskCanceled, true},
+		{TaskSkipped, true},
+	}
+
+	for _, tt := range tests {
+		if got := (&Task{State: tt.state}).Finished(); got != tt.want {
+			t.Errorf("Finished() in state %q = %v, want %v", tt.state, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp3
+
+import (
+	"github.com/msutter/go-pulp/pulp"
+)
+
+// download policies of a remote
+const (
+	PolicyImmediate = "immediate"
+	PolicyOnDemand  = "on_demand"
+	PolicyStreamed  = "streamed"
+)
+
+type RemotesService struct {
+	client *Client
+}
+
+type Remote struct {
+	Href                string `json:"pulp_href"`
+	Created             string `json:"pulp_created"`
+	LastUpdated         string `json:"pulp_last_updated"`
+	Name                string `json:"name"`
+	Url                 string `json:"url"`
+	CaCert              string `json:"ca_cert"`
+	ClientCert          string `json:"client_cert"`
+	TlsValidation       bool   `json:"tls_validation"`
+	ProxyUrl            string `json:"proxy_url"`
+	Policy              string `json:"policy"`
+	DownloadConcurrency int    `json:"download_concurrency"`
+}
+
+func (r Remote) String() string {
+	return pulp.Stringify(r)
+}
+
+type remoteList struct {
+	Count   int       `json:"count"`
+	Results []*Remote `json:"results"`
+}
+
+// Pulp Api docs:
+// https://docs.pulpproject.org/pulpcore/restapi.html#tag/Remotes
+func (s *RemotesService) ListRemotes(opt *ListOptions) ([]*Remote, *pulp.Response, error) {
+	l := new(remoteList)
+	resp, err := s.client.get("remotes/", opt, l)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return l.Results, resp, err
+}
+
+func (s *RemotesService) GetRemote(href string) (*Remote, *pulp.Response, error) {
+	r := new(Remote)
+	resp, err := s.client.get(href, nil, r)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return r, resp, err
+}
+
+type RemoteOptions struct {
+	Name                string `json:"name,omitempty"`
+	Url                 string `json:"url,omitempty"`
+	CaCert              string `json:"ca_cert,omitempty"`
+	ClientCert          string `json:"client_cert,omitempty"`
+	ClientKey           string `json:"client_key,omitempty"`
+	TlsValidation       *bool  `json:"tls_validation,omitempty"`
+	ProxyUrl            string `json:"proxy_url,omitempty"`
+	Username            string `json:"username,omitempty"`
+	Password            string `json:"password,omitempty"`
+	Policy              string `json:"policy,omitempty"`
+	DownloadConcurrency int    `json:"download_concurrency,omitempty"`
+}
+
+// CreateRemote creates a remote of the given plugin type, e.g. "rpm/rpm".
+func (s *RemotesService) CreateRemote(pluginType string, opt *RemoteOptions) (*Remote, *pulp.Response, error) {
+	r := new(Remote)
+	resp, err := s.client.send("POST", "remotes/"+pluginType+"/", opt, r)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return r, resp, err
+}
+
+func (s *RemotesService) UpdateRemote(href string, opt *RemoteOptions) (*AsyncOperationResponse, *pulp.Response, error) {
+	return s.client.async("PATCH", href, opt)
+}
+
+func (s *RemotesService) DeleteRemote(href string) (*AsyncOperationResponse, *pulp.Response, error) {
+	return s.client.async("DELETE", href, nil)
+}
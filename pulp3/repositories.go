@@ -0,0 +1,155 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp3
+
+import (
+	"github.com/msutter/go-pulp/pulp"
+)
+
+type RepositoriesService struct {
+	client *Client
+}
+
+type Repository struct {
+	Href              string `json:"pulp_href"`
+	Created           string `json:"pulp_created"`
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	Remote            string `json:"remote"`
+	VersionsHref      string `json:"versions_href"`
+	LatestVersionHref string `json:"latest_version_href"`
+}
+
+func (r Repository) String() string {
+	return pulp.Stringify(r)
+}
+
+type ContentCount struct {
+	Count int    `json:"count"`
+	Href  string `json:"href"`
+}
+
+type ContentSummary struct {
+	Added   map[string]*ContentCount `json:"added"`
+	Removed map[string]*ContentCount `json:"removed"`
+	Present map[string]*ContentCount `json:"present"`
+}
+
+type RepositoryVersion struct {
+	Href           string          `json:"pulp_href"`
+	Created        string          `json:"pulp_created"`
+	Number         int             `json:"number"`
+	Repository     string          `json:"repository"`
+	BaseVersion    string          `json:"base_version"`
+	ContentSummary *ContentSummary `json:"content_summary"`
+}
+
+func (v RepositoryVersion) String() string {
+	return pulp.Stringify(v)
+}
+
+type repositoryList struct {
+	Count   int           `json:"count"`
+	Results []*Repository `json:"results"`
+}
+
+type repositoryVersionList struct {
+	Count   int                  `json:"count"`
+	Results []*RepositoryVersion `json:"results"`
+}
+
+// Pulp Api docs:
+// https://docs.pulpproject.org/pulpcore/restapi.html#tag/Repositories
+func (s *RepositoriesService) ListRepositories(opt *ListOptions) ([]*Repository, *pulp.Response, error) {
+	l := new(repositoryList)
+	resp, err := s.client.get("repositories/", opt, l)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return l.Results, resp, err
+}
+
+func (s *RepositoriesService) GetRepository(href string) (*Repository, *pulp.Response, error) {
+	r := new(Repository)
+	resp, err := s.client.get(href, nil, r)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return r, resp, err
+}
+
+type RepositoryOptions struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Remote      string `json:"remote,omitempty"`
+}
+
+// CreateRepository creates a repository of the given plugin type, e.g.
+// "rpm/rpm".
+func (s *RepositoriesService) CreateRepository(pluginType string, opt *RepositoryOptions) (*Repository, *pulp.Response, error) {
+	r := new(Repository)
+	resp, err := s.client.send("POST", "repositories/"+pluginType+"/", opt, r)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return r, resp, err
+}
+
+func (s *RepositoriesService) UpdateRepository(href string, opt *RepositoryOptions) (*AsyncOperationResponse, *pulp.Response, error) {
+	return s.client.async("PATCH", href, opt)
+}
+
+func (s *RepositoriesService) DeleteRepository(href string) (*AsyncOperationResponse, *pulp.Response, error) {
+	return s.client.async("DELETE", href, nil)
+}
+
+type SyncOptions struct {
+	Remote string `json:"remote,omitempty"`
+	Mirror bool   `json:"mirror"`
+}
+
+// SyncRepository syncs the repository from a remote, creating a new
+// repository version.
+func (s *RepositoriesService) SyncRepository(href string, opt *SyncOptions) (*AsyncOperationResponse, *pulp.Response, error) {
+	if opt == nil {
+		opt = &SyncOptions{}
+	}
+	return s.client.async("POST", href+"sync/", opt)
+}
+
+func (s *RepositoriesService) ListRepositoryVersions(href string, opt *ListOptions) ([]*RepositoryVersion, *pulp.Response, error) {
+	l := new(repositoryVersionList)
+	resp, err := s.client.get(href+"versions/", opt, l)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return l.Results, resp, err
+}
+
+func (s *RepositoriesService) GetRepositoryVersion(href string) (*RepositoryVersion, *pulp.Response, error) {
+	v := new(RepositoryVersion)
+	resp, err := s.client.get(href, nil, v)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return v, resp, err
+}
@@ -0,0 +1,123 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package pulp3
+
+import (
+	"github.com/msutter/go-pulp/pulp"
+)
+
+// states of a task
+const (
+	TaskWaiting   = "waiting"
+	TaskRunning   = "running"
+	TaskCompleted = "completed"
+	TaskFailed    = "failed"
+	TaskCanceling = "canceling"
+	TaskCanceled  = "canceled"
+	TaskSkipped   = "skipped"
+)
+
+type TasksService struct {
+	client *Client
+}
+
+type ProgressReport struct {
+	Message string `json:"message"`
+	Code    string `json:"code"`
+	State   string `json:"state"`
+	Total   int    `json:"total"`
+	Done    int    `json:"done"`
+	Suffix  string `json:"suffix"`
+}
+
+type Task struct {
+	Href             string                 `json:"pulp_href"`
+	Created          string                 `json:"pulp_created"`
+	State            string                 `json:"state"`
+	Name             string                 `json:"name"`
+	LoggingCid       string                 `json:"logging_cid"`
+	StartedAt        pulp.PulpTime          `json:"started_at"`
+	FinishedAt       pulp.PulpTime          `json:"finished_at"`
+	Error            map[string]interface{} `json:"error"`
+	Worker           string                 `json:"worker"`
+	ParentTask       string                 `json:"parent_task"`
+	ChildTasks       []string               `json:"child_tasks"`
+	TaskGroup        string                 `json:"task_group"`
+	ProgressReports  []*ProgressReport      `json:"progress_reports"`
+	CreatedResources []string               `json:"created_resources"`
+}
+
+func (t Task) String() string {
+	return pulp.Stringify(t)
+}
+
+// Finished reports whether the task reached a final state. A canceling
+// task is still running until its worker stops it.
+func (t *Task) Finished() bool {
+	switch t.State {
+	case TaskCompleted, TaskFailed, TaskCanceled, TaskSkipped:
+		return true
+	}
+	return false
+}
+
+type ListTasksOptions struct {
+	ListOptions
+	State string `url:"state,omitempty" json:"state,omitempty"`
+	Name  string `url:"name,omitempty" json:"name,omitempty"`
+}
+
+type taskList struct {
+	Count   int     `json:"count"`
+	Results []*Task `json:"results"`
+}
+
+// Pulp Api docs:
+// https://docs.pulpproject.org/pulpcore/restapi.html#tag/Tasks
+func (s *TasksService) ListTasks(opt *ListTasksOptions) ([]*Task, *pulp.Response, error) {
+	l := new(taskList)
+	resp, err := s.client.get("tasks/", opt, l)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return l.Results, resp, err
+}
+
+func (s *TasksService) GetTask(href string) (*Task, *pulp.Response, error) {
+	t := new(Task)
+	resp, err := s.client.get(href, nil, t)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return t, resp, err
+}
+
+type cancelTaskRequest struct {
+	State string `json:"state"`
+}
+
+func (s *TasksService) CancelTask(href string) (*Task, *pulp.Response, error) {
+	t := new(Task)
+	resp, err := s.client.send("PATCH", href, &cancelTaskRequest{State: TaskCanceled}, t)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return t, resp, err
+}
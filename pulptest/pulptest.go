@@ -0,0 +1,341 @@
+//
+// Copyright 2016, Marc Sutter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+// Package pulptest provides a fake pulp server for testing code which uses
+// the pulp client, without a live pulp instance.
+//
+//	srv := pulptest.NewServer()
+//	defer srv.Close()
+//
+//	srv.AddRepository(&pulp.Repository{Id: "zoo"})
+//	client, err := srv.Client()
+//
+// Requests to paths without a fixture or handler are answered with a 404
+// pulp error. All requests are recorded and returned by Requests.
+package pulptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/msutter/go-pulp/pulp"
+)
+
+const apiPath = "/pulp/api/v2/"
+
+// Request is a request received by the server.
+type Request struct {
+	Method string
+	// path relative to the api, e.g. repositories/zoo/
+	Path  string
+	Query url.Values
+	Body  []byte
+}
+
+// DecodeBody decodes the json body of the request into v.
+func (r *Request) DecodeBody(v interface{}) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+type Server struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	handlers     map[string]http.HandlerFunc
+	requests     []*Request
+	repositories map[string]*pulp.Repository
+	tasks        map[string]*pulp.Task
+	units        map[string][]*pulp.Unit
+	taskCount    int
+}
+
+// NewServer starts a fake pulp server. It must be closed with Close.
+func NewServer() *Server {
+	s := &Server{
+		handlers:     make(map[string]http.HandlerFunc),
+		repositories: make(map[string]*pulp.Repository),
+		tasks:        make(map[string]*pulp.Task),
+		units:        make(map[string][]*pulp.Unit),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a client for the server. The options are applied after
+// the ones needed to reach the server.
+func (s *Server) Client(options ...pulp.ClientOption) (*pulp.Client, error) {
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return nil, err
+	}
+	return pulp.NewClient(u.Host, append([]pulp.ClientOption{pulp.WithoutSSL()}, options...)...)
+}
+
+// HandleFunc handles the requests to the api path, e.g.
+// HandleFunc("POST", "repositories/", h). A handler takes precedence over
+// the fixtures.
+func (s *Server) HandleFunc(method string, path string, h http.HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method+" "+path] = h
+}
+
+// Handle answers the requests to the api path with the status and v
+// encoded as json.
+func (s *Server) Handle(method string, path string, status int, v interface{}) {
+	s.HandleFunc(method, path, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, status, v)
+	})
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []*Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Request(nil), s.requests...)
+}
+
+// Reset forgets the recorded requests.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+// AddRepository adds or replaces a repository fixture. It is returned by
+// the repository list, get and search calls, and can be synced, published
+// and deleted.
+func (s *Server) AddRepository(r *pulp.Repository) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repositories[r.Id] = r
+}
+
+// AddTask adds or replaces a task fixture.
+func (s *Server) AddTask(t *pulp.Task) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks[t.Id] = t
+}
+
+// AddUnits adds unit fixtures to the repository. They are returned by the
+// unit searches of the repository, filtered on their type only.
+func (s *Server) AddUnits(repository string, units ...*pulp.Unit) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range units {
+		c := *u
+		c.RepoId = repository
+		if len(c.RawMetadata) == 0 && c.Metadata != nil {
+			data, err := json.Marshal(c.Metadata)
+			if err != nil {
+				return err
+			}
+			c.RawMetadata = data
+		}
+		s.units[repository] = append(s.units[repository], &c)
+	}
+	return nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	path := strings.TrimPrefix(r.URL.Path, apiPath)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, &Request{
+		Method: r.Method,
+		Path:   path,
+		Query:  r.URL.Query(),
+		Body:   body,
+	})
+	h := s.handlers[r.Method+" "+path]
+	s.mu.Unlock()
+
+	if h != nil {
+		r.Body = ioutil.NopCloser(strings.NewReader(string(body)))
+		h(w, r)
+		return
+	}
+
+	s.serveFixtures(w, r.Method, strings.Split(strings.Trim(path, "/"), "/"), body)
+}
+
+func (s *Server) serveFixtures(w http.ResponseWriter, method string, parts []string, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case match(parts, "repositories") && method == "GET",
+		match(parts, "repositories", "search") && method == "POST":
+		var repos []*pulp.Repository
+		for _, id := range sortedKeys(s.repositories) {
+			repos = append(repos, s.repositories[id])
+		}
+		writeJSON(w, http.StatusOK, repos)
+		return
+
+	case match(parts, "repositories", "*"):
+		r, ok := s.repositories[parts[1]]
+		if !ok {
+			break
+		}
+		switch method {
+		case "GET":
+			writeJSON(w, http.StatusOK, r)
+			return
+		case "DELETE":
+			delete(s.repositories, r.Id)
+			delete(s.units, r.Id)
+			writeJSON(w, http.StatusAccepted, s.spawnTask("pulp:action:delete", r.Id))
+			return
+		}
+
+	case match(parts, "repositories", "*", "actions", "*") && method == "POST":
+		if _, ok := s.repositories[parts[1]]; !ok {
+			break
+		}
+		writeJSON(w, http.StatusAccepted, s.spawnTask("pulp:action:"+parts[3], parts[1]))
+		return
+
+	case match(parts, "repositories", "*", "search", "units") && method == "POST":
+		if _, ok := s.repositories[parts[1]]; !ok {
+			break
+		}
+		var search struct {
+			Criteria struct {
+				TypeIds []string `json:"type_ids"`
+			} `json:"criteria"`
+		}
+		json.Unmarshal(body, &search)
+
+		units := []*pulp.Unit{}
+		for _, u := range s.units[parts[1]] {
+			if len(search.Criteria.TypeIds) == 0 || contains(search.Criteria.TypeIds, u.UnitTypeId) {
+				units = append(units, u)
+			}
+		}
+		writeJSON(w, http.StatusOK, units)
+		return
+
+	case match(parts, "tasks") && method == "GET":
+		var tasks []*pulp.Task
+		for _, id := range sortedKeys(s.tasks) {
+			tasks = append(tasks, s.tasks[id])
+		}
+		writeJSON(w, http.StatusOK, tasks)
+		return
+
+	case match(parts, "tasks", "*"):
+		t, ok := s.tasks[parts[1]]
+		if !ok {
+			break
+		}
+		switch method {
+		case "GET":
+			writeJSON(w, http.StatusOK, t)
+			return
+		case "DELETE":
+			if !t.Finished() {
+				t.State = pulp.TaskCanceled
+			}
+			writeJSON(w, http.StatusOK, nil)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusNotFound, map[string]interface{}{
+		"http_status":   http.StatusNotFound,
+		"error_message": fmt.Sprintf("Missing resource(s): %s", strings.Join(parts, "/")),
+		"error": map[string]interface{}{
+			"code":        "PLP0009",
+			"description": "Missing resource(s)",
+		},
+	})
+}
+
+// spawnTask adds a finished task for an action on the repository and
+// returns its call report.
+func (s *Server) spawnTask(taskType string, repository string) *pulp.CallReport {
+	s.taskCount++
+	id := fmt.Sprintf("task-%d", s.taskCount)
+
+	s.tasks[id] = &pulp.Task{
+		Id:       id,
+		TaskType: taskType,
+		Tags:     []string{pulp.RepositoryTag(repository), pulp.ActionTag(strings.TrimPrefix(taskType, "pulp:action:"))},
+		State:    pulp.TaskFinished,
+	}
+
+	return &pulp.CallReport{
+		SpawnedTasks: []pulp.SpawnedTask{{
+			Href:   apiPath + "tasks/" + id + "/",
+			TaskId: id,
+		}},
+	}
+}
+
+// match reports if the path parts match the pattern, * matching any part
+func match(parts []string, pattern ...string) bool {
+	if len(parts) != len(pattern) {
+		return false
+	}
+	for i, p := range pattern {
+		if p != "*" && p != parts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]*pulp.Repository:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]*pulp.Task:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}